@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// APIKey APIのAPIキーのレスポンスの構造体
+// Keyは発行時のみ返却する
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyPayload APIキー発行の際のPayload
+type APIKeyPayload struct {
+	Name      string     `json:"name" binding:"required,max=50"`
+	Scope     string     `json:"scope" binding:"required,oneof=read read_write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func toAPIKeyResponse(apiKey model.APIKey) APIKey {
+	return APIKey{
+		ID:         int(apiKey.ID),
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scope:      apiKey.Scope,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+// GetAPIKeyList ではログインユーザーのAPIキーの一覧を取得する
+func (h *Handler) GetAPIKeyList(c *gin.Context) {
+	keys, err := h.apiKeys.GetAPIKeyList(c.Request.Context(), middleware.GetLoginUser(c).ID)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "API key not found"})
+		return
+	}
+	result := []APIKey{}
+	for _, v := range keys {
+		result = append(result, toAPIKeyResponse(v))
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// CreateAPIKey ではログインユーザーのAPIキーを発行する
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var payload APIKeyPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: expires_at must be in the future"})
+		return
+	}
+
+	newKey, key, err := h.apiKeys.AddNewAPIKey(c.Request.Context(), middleware.GetLoginUser(c).ID, model.APIKeyPayload{
+		Name:      payload.Name,
+		Scope:     payload.Scope,
+		ExpiresAt: payload.ExpiresAt,
+	})
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to create api key"})
+		return
+	}
+	res := toAPIKeyResponse(newKey)
+	res.Key = key
+	c.IndentedJSON(http.StatusCreated, res)
+}
+
+// DeleteAPIKey ではIDで指定されたAPIキーを失効させる
+func (h *Handler) DeleteAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+
+	deleted, err := h.apiKeys.DeleteAPIKey(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target api key is not found"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to delete api key"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toAPIKeyResponse(deleted))
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// ArchiveCompletedPayload 完了したTODOをまとめてアーカイブする際のPayload
+// older_than_days 日より前に完了したTODOが対象で、0の場合は完了した全てのTODOが対象
+type ArchiveCompletedPayload struct {
+	OlderThanDays *int `json:"older_than_days" binding:"required,min=0,max=3650"`
+}
+
+// ArchiveResult まとめてアーカイブしたTODOの件数とID
+type ArchiveResult struct {
+	Count int   `json:"count"`
+	IDs   []int `json:"ids"`
+}
+
+// ArchiveCompletedTodo ではログインユーザーの指定の日数より前に完了したItemをまとめてアーカイブする
+func (h *Handler) ArchiveCompletedTodo(c *gin.Context) {
+	var payload ArchiveCompletedPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+
+	before := time.Now().AddDate(0, 0, -*payload.OlderThanDays)
+	ids, err := h.todos.ArchiveCompleted(c.Request.Context(), middleware.GetLoginUser(c).ID, before)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to archive items"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, ArchiveResult{Count: len(ids), IDs: toIDs(ids)})
+}
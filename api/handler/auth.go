@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/lib/util"
+)
+
+// Token APIのトークン発行のレスポンスの構造体
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// TokenPayload トークン発行の際のPayload
+type TokenPayload struct {
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshPayload トークン再発行及びログアウトの際のPayload
+type RefreshPayload struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// issueTokenPair はアクセストークンとリフレッシュトークンを発行する
+func (h *Handler) issueTokenPair(userID uint) (Token, error) {
+	accessToken, _, err := util.IssueToken(h.cfg.Auth, userID, util.AccessTokenType)
+	if err != nil {
+		return Token{}, err
+	}
+	refreshToken, _, err := util.IssueToken(h.cfg.Auth, userID, util.RefreshTokenType)
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(h.cfg.Auth.AccessTokenTTL / time.Second),
+	}, nil
+}
+
+// IssueToken ではユーザー名とパスワードをアクセストークンとリフレッシュトークンに交換する
+func (h *Handler) IssueToken(c *gin.Context) {
+	var payload TokenPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+
+	user, ok := h.users.CheckUserAuth(c.Request.Context(), payload.Name, payload.Password)
+	if !ok {
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+		return
+	}
+	token, err := h.issueTokenPair(user.ID)
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "fail to issue token"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, token)
+}
+
+// RefreshToken ではリフレッシュトークンを失効させて新しいトークンを発行する
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var payload RefreshPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+	claims, err := util.ParseToken(h.cfg.Auth, payload.RefreshToken, util.RefreshTokenType)
+	if err != nil {
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+		return
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+		return
+	}
+
+	if revoked, err := h.tokens.IsTokenRevoked(c.Request.Context(), claims.ID); err != nil || revoked {
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+		return
+	}
+	user, err := h.users.GetUserByID(c.Request.Context(), userID)
+	if err != nil || claims.IssuedBefore(user.PasswordChangedAt) {
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+		return
+	}
+	// Note: 同じトークンで同時に再発行された場合、失効の登録に成功した一方のみに発行する
+	revoked, err := h.tokens.RevokeToken(c.Request.Context(), claims.ID, userID, claims.ExpiresAt.Time)
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "fail to revoke token"})
+		return
+	}
+	if !revoked {
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+		return
+	}
+	token, err := h.issueTokenPair(userID)
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "fail to issue token"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, token)
+}
+
+// Logout ではリフレッシュトークンを失効させる
+func (h *Handler) Logout(c *gin.Context) {
+	var payload RefreshPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+	claims, err := util.ParseToken(h.cfg.Auth, payload.RefreshToken, util.RefreshTokenType)
+	if err != nil {
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+		return
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+		return
+	}
+
+	if _, err := h.tokens.RevokeToken(c.Request.Context(), claims.ID, userID, claims.ExpiresAt.Time); err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "fail to revoke token"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// 一括処理のモード
+const (
+	// BulkModeAtomic は1件でも失敗した場合に全ての操作を取り消す
+	BulkModeAtomic = "atomic"
+	// BulkModeBestEffort は失敗した操作のみを取り消し、残りの操作を反映する
+	BulkModeBestEffort = "best_effort"
+)
+
+// BulkPayload 一括処理のPayload
+// Mode を省略した場合は atomic とする
+type BulkPayload struct {
+	Mode       string                 `json:"mode"`
+	Operations []BulkOperationPayload `json:"operations" binding:"required"`
+}
+
+// BulkOperationPayload 一括処理の1件の操作
+// Op は create, update, status, delete のいずれかで、ID は create 以外で、Todo は create 及び update で、Status は status で指定する
+type BulkOperationPayload struct {
+	Op               string   `json:"op"`
+	ID               uint     `json:"id"`
+	Todo             *Payload `json:"todo"`
+	Status           string   `json:"status"`
+	CompleteChildren bool     `json:"complete_children"`
+}
+
+// BulkItemResult 一括処理の1件の操作の結果
+// Status は同じ操作を単体のAPIで実行した場合のステータスコードで、取り消された操作は424とする
+type BulkItemResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Status int    `json:"status"`
+	Todo   *Todo  `json:"todo,omitempty"`
+	Error  gin.H  `json:"error,omitempty"`
+}
+
+// BulkResponse 一括処理のレスポンス
+type BulkResponse struct {
+	Mode      string           `json:"mode"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// bulkNotApplied は全ての操作を取り消した場合の、失敗した操作以外の結果
+var bulkNotApplied = gin.H{"message": "Failed Dependency: operation was not applied"}
+
+// parseBulkOperation は一括処理の1件の操作を検証して変換する
+func parseBulkOperation(op BulkOperationPayload) (model.BulkOperation, *apiError) {
+	parsed := model.BulkOperation{Op: op.Op, ID: op.ID}
+	switch op.Op {
+	case model.BulkCreate, model.BulkUpdate, model.BulkStatus, model.BulkDelete:
+	default:
+		return parsed, &apiError{http.StatusBadRequest, gin.H{
+			"message":   "Bad Request: unknown op " + op.Op,
+			"valid_ops": []string{model.BulkCreate, model.BulkUpdate, model.BulkStatus, model.BulkDelete},
+		}}
+	}
+	if op.Op != model.BulkCreate && op.ID == 0 {
+		return parsed, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: id is required"}}
+	}
+	switch op.Op {
+	case model.BulkCreate, model.BulkUpdate:
+		if op.Todo == nil || binding.Validator.ValidateStruct(op.Todo) != nil {
+			return parsed, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"}}
+		}
+		payload, apiErr := parsePayload(*op.Todo)
+		if apiErr != nil {
+			return parsed, apiErr
+		}
+		parsed.Payload = payload
+	case model.BulkStatus:
+		if op.Status == "" {
+			return parsed, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: status is required"}}
+		}
+		parsed.Status = model.Status{Status: op.Status, CompleteChildren: op.CompleteChildren}
+	}
+	return parsed, nil
+}
+
+// bulkOperationResult は成功した操作の結果を単体のAPIと同じステータスコードで返却する
+func bulkOperationResult(op string, todo model.Todo) BulkItemResult {
+	status := http.StatusOK
+	if op == model.BulkCreate {
+		status = http.StatusCreated
+	}
+	response := toTodoResponse(todo)
+	return BulkItemResult{Op: op, Status: status, Todo: &response}
+}
+
+// bulkOperationError は失敗した操作の結果を単体のAPIと同じエラーで返却する
+func bulkOperationError(op string, err error) BulkItemResult {
+	fallback := "fail to update item"
+	if op == model.BulkCreate {
+		fallback = "fail to create new item"
+	}
+	apiErr := todoError(err, fallback)
+	return BulkItemResult{Op: op, Status: apiErr.status, Error: apiErr.body}
+}
+
+// BulkTodo では複数のItemの作成、更新、Statusの変更及び削除を1つのトランザクションで実行し、操作毎の結果を返却する
+// atomic の場合は全て成功すると200、失敗すると失敗した操作のステータスコードで全ての操作を取り消す
+// best_effort の場合は全て成功すると200、1件でも失敗すると207とする
+func (h *Handler) BulkTodo(c *gin.Context) {
+	var payload BulkPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+	if payload.Mode == "" {
+		payload.Mode = BulkModeAtomic
+	}
+	if payload.Mode != BulkModeAtomic && payload.Mode != BulkModeBestEffort {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{
+			"message":     "Bad Request: unknown mode " + payload.Mode,
+			"valid_modes": []string{BulkModeAtomic, BulkModeBestEffort},
+		})
+		return
+	}
+	if len(payload.Operations) == 0 || len(payload.Operations) > model.MaxBulkOperations {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{
+			"message": fmt.Sprintf("Bad Request: operations must contain 1 to %d items", model.MaxBulkOperations),
+		})
+		return
+	}
+	atomic := payload.Mode == BulkModeAtomic
+
+	results := make([]BulkItemResult, len(payload.Operations))
+	ops := []model.BulkOperation{}
+	// indexes は検証に成功した操作の、リクエスト中の位置
+	indexes := []int{}
+	invalid := false
+	for i, v := range payload.Operations {
+		op, apiErr := parseBulkOperation(v)
+		if apiErr != nil {
+			results[i] = BulkItemResult{Op: v.Op, Status: apiErr.status, Error: apiErr.body}
+			invalid = true
+			continue
+		}
+		ops = append(ops, op)
+		indexes = append(indexes, i)
+	}
+
+	// Note: atomic の場合は検証に失敗した操作があればリポジトリを呼び出さない
+	if len(ops) > 0 && !(atomic && invalid) {
+		bulkResults, err := h.todos.Bulk(c.Request.Context(), middleware.GetLoginUser(c).ID, ops, atomic)
+		if err != nil {
+			c.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "fail to process operations"})
+			return
+		}
+		for j, r := range bulkResults {
+			if r.Err != nil {
+				results[indexes[j]] = bulkOperationError(ops[j].Op, r.Err)
+				invalid = true
+			} else {
+				results[indexes[j]] = bulkOperationResult(ops[j].Op, r.Todo)
+			}
+		}
+	}
+
+	response := BulkResponse{Mode: payload.Mode, Results: results}
+	status := http.StatusOK
+	for i := range response.Results {
+		r := &response.Results[i]
+		r.Index = i
+		if r.Error == nil && atomic && invalid {
+			// Note: 全ての操作を取り消したため、成功した操作及び実行しなかった操作の結果を置き換える
+			*r = BulkItemResult{Index: i, Op: payload.Operations[i].Op, Status: http.StatusFailedDependency, Error: bulkNotApplied}
+		}
+		if r.Error != nil {
+			response.Failed++
+			if status == http.StatusOK && r.Status != http.StatusFailedDependency {
+				status = r.Status
+			}
+		} else {
+			response.Succeeded++
+		}
+	}
+	if !atomic && response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.IndentedJSON(status, response)
+}
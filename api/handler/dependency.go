@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// DependencyPayload TODOが終了を待つTODO (Blocker) を追加する際のPayload
+type DependencyPayload struct {
+	BlockerID uint `json:"blocker_id" binding:"required"`
+}
+
+// AddDependency ではIDで指定されたItemが終了を待つItemを追加する
+// 終了していないBlockerを持つItemは完了にできない
+func (h *Handler) AddDependency(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+	var payload DependencyPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+
+	updated, err := h.todos.AddDependency(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id), payload.BlockerID)
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target item is not found"})
+		return
+	}
+	if writeReferenceError(c, err) {
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to add dependency"})
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, toTodoResponse(updated))
+}
+
+// RemoveDependency ではIDで指定されたItemから、終了を待つItemを外す
+func (h *Handler) RemoveDependency(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+	blockerID, err := strconv.Atoi(c.Param("blocker_id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: blocker_id"})
+		return
+	}
+
+	updated, err := h.todos.RemoveDependency(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id), uint(blockerID))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target dependency is not found"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to remove dependency"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toTodoResponse(updated))
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+)
+
+// queryValues はクエリパラメータの値を取得する
+// 同じキーの繰り返しとカンマ区切りの両方に対応する
+func queryValues(c *gin.Context, key string) []string {
+	values := []string{}
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			values = append(values, strings.TrimSpace(v))
+		}
+	}
+	return values
+}
+
+func containsValue(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// queryTime はRFC3339形式の日時のクエリパラメータを取得する
+func queryTime(c *gin.Context, key string) (*time.Time, bool) {
+	raw, ok := c.GetQuery(key)
+	if !ok {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, false
+	}
+	return &t, true
+}
+
+// parseTodoFilter はクエリパラメータからTodoリストの絞り込み条件を生成する
+// status はユーザーのワークフローにあるStatusのみ指定できる
+// 不正なパラメータがある場合はそのパラメータ名の一覧を返却する
+func parseTodoFilter(c *gin.Context, workflow model.Workflow) (model.TodoFilter, []string) {
+	filter := model.TodoFilter{}
+	invalid := []string{}
+
+	filter.Statuses = queryValues(c, "status")
+	for _, v := range filter.Statuses {
+		if !workflow.HasState(v) {
+			invalid = append(invalid, "status")
+			break
+		}
+	}
+	for _, v := range queryValues(c, "priority") {
+		priority, err := model.ParsePriority(v)
+		if err != nil {
+			invalid = append(invalid, "priority")
+			break
+		}
+		filter.Priorities = append(filter.Priorities, priority)
+	}
+
+	ranges := []struct {
+		after, before   string
+		afterT, beforeT **time.Time
+	}{
+		{"created_after", "created_before", &filter.CreatedAfter, &filter.CreatedBefore},
+		{"updated_after", "updated_before", &filter.UpdatedAfter, &filter.UpdatedBefore},
+		{"due_after", "due_before", &filter.DueAfter, &filter.DueBefore},
+	}
+	for _, r := range ranges {
+		after, ok := queryTime(c, r.after)
+		if !ok {
+			invalid = append(invalid, r.after)
+		}
+		before, ok := queryTime(c, r.before)
+		if !ok {
+			invalid = append(invalid, r.before)
+		}
+		if after != nil && before != nil && !before.After(*after) {
+			invalid = append(invalid, r.before)
+		}
+		*r.afterT, *r.beforeT = after, before
+	}
+
+	for _, v := range queryValues(c, "tag") {
+		name, err := model.NormalizeTagName(v)
+		if err != nil {
+			invalid = append(invalid, "tag")
+			break
+		}
+		if !containsValue(filter.Tags, name) {
+			filter.Tags = append(filter.Tags, name)
+		}
+	}
+	switch c.DefaultQuery("tag_match", "any") {
+	case "any":
+	case "all":
+		filter.TagsMatchAll = true
+	default:
+		invalid = append(invalid, "tag_match")
+	}
+
+	for _, key := range []struct {
+		name string
+		id   **uint
+	}{
+		{"project_id", &filter.ProjectID},
+		{"parent_id", &filter.ParentID},
+	} {
+		raw, ok := c.GetQuery(key.name)
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			invalid = append(invalid, key.name)
+		}
+		id := uint(parsed)
+		*key.id = &id
+	}
+
+	if raw, ok := c.GetQuery("archived"); ok {
+		archived, err := strconv.ParseBool(raw)
+		if err != nil {
+			invalid = append(invalid, "archived")
+		}
+		filter.Archived = archived
+	}
+
+	if title, ok := c.GetQuery("title"); ok {
+		if strings.TrimSpace(title) == "" {
+			invalid = append(invalid, "title")
+		}
+		filter.Title = title
+	}
+	return filter, invalid
+}
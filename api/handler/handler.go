@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/repository"
+)
+
+// Handler は各APIのハンドラーが共有する設定とRepositoryを保持する
+type Handler struct {
+	cfg       config.Config
+	todos     repository.TodoRepository
+	users     repository.UserRepository
+	tokens    repository.TokenRepository
+	apiKeys   repository.APIKeyRepository
+	workflows repository.WorkflowRepository
+	tags      repository.TagRepository
+	projects  repository.ProjectRepository
+}
+
+// New は設定と起動時に生成したRepositoryをもとにHandlerを生成する
+func New(cfg config.Config, repos repository.Repositories) *Handler {
+	return &Handler{
+		cfg:       cfg,
+		todos:     repos.Todos,
+		users:     repos.Users,
+		tokens:    repos.Tokens,
+		apiKeys:   repos.APIKeys,
+		workflows: repos.Workflows,
+		tags:      repos.Tags,
+		projects:  repos.Projects,
+	}
+}
+
+// isNotFound はログインユーザーの対象Itemが存在しないエラーかを判定する
+func isNotFound(err error) bool {
+	return errors.Is(err, repository.ErrNotFound)
+}
+
+// apiError はレスポンスに書き込むエラーのステータスコードとBody
+// 一括処理では操作毎の結果に含める
+type apiError struct {
+	status int
+	body   gin.H
+}
+
+func (e *apiError) write(c *gin.Context) {
+	c.IndentedJSON(e.status, e.body)
+}
+
+// writeWorkflowError はワークフローのエラーをレスポンスに書き込む
+// エラーを書き込んだ場合はtrueを返却する
+func writeWorkflowError(c *gin.Context, err error) bool {
+	if apiErr := workflowError(err); apiErr != nil {
+		apiErr.write(c)
+		return true
+	}
+	return false
+}
+
+// workflowError はワークフローに違反するStatusの指定、未完了の子孫または終了していないBlockerを持つItemの完了及び同時更新のエラーを変換する
+// いずれでもない場合はnilを返却する
+func workflowError(err error) *apiError {
+	var statusErr *model.StatusError
+	var transitionErr *model.TransitionError
+	var openChildrenErr *model.OpenChildrenError
+	var openBlockersErr *model.OpenBlockersError
+	switch {
+	case errors.As(err, &statusErr):
+		return &apiError{http.StatusBadRequest, gin.H{
+			"message":        "Bad Request: unknown status " + statusErr.Status,
+			"valid_statuses": statusErr.Valid,
+		}}
+	case errors.As(err, &transitionErr):
+		return &apiError{http.StatusConflict, gin.H{
+			"message":          "Conflict: cannot change status from " + transitionErr.From + " to " + transitionErr.To,
+			"allowed_statuses": transitionErr.Allowed,
+		}}
+	case errors.As(err, &openChildrenErr):
+		return &apiError{http.StatusConflict, gin.H{
+			"message":       "Conflict: item has open subtasks",
+			"open_children": openChildrenErr.IDs,
+		}}
+	case errors.As(err, &openBlockersErr):
+		return &apiError{http.StatusConflict, gin.H{
+			"message":       "Conflict: item is blocked by open items",
+			"open_blockers": openBlockersErr.IDs,
+		}}
+	case errors.Is(err, repository.ErrConflict):
+		return &apiError{http.StatusConflict, gin.H{"message": "Conflict: target item was modified concurrently"}}
+	default:
+		return nil
+	}
+}
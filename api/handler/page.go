@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+)
+
+const (
+	// defaultPageLimit はlimit未指定時に返却する件数
+	defaultPageLimit = 100
+	// maxPageLimit はlimitに指定できる最大件数
+	maxPageLimit = 1000
+)
+
+// todoCursor はページの続きを表すカーソルの中身
+// 直前のページ末尾のItemのうち、並び替えに利用する項目の値のみを保持する
+type todoCursor struct {
+	Sort      string     `json:"s"`
+	ID        uint       `json:"id"`
+	Title     string     `json:"title,omitempty"`
+	Status    string     `json:"status,omitempty"`
+	Priority  string     `json:"priority,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	DueAt     *time.Time `json:"due_at,omitempty"`
+}
+
+// scheduleCursorSort は期限の近い順のTodoリストのカーソルに記録する並び替え条件
+const scheduleCursorSort = "due_at,id"
+
+// encode はカーソルをクエリパラメータに指定できる文字列に変換する
+func (cursor todoCursor) encode() string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// parseCursor はカーソルの文字列を解釈する
+// カーソル生成時と並び替え条件が異なる場合はエラーとする
+func parseCursor(sort string, value string) (todoCursor, error) {
+	cursor := todoCursor{}
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.Sort != sort {
+		return cursor, fmt.Errorf("cursor was issued for sort %q", cursor.Sort)
+	}
+	return cursor, nil
+}
+
+// encodeCursor はページ末尾のItemから次のページのカーソルを生成する
+func encodeCursor(sort model.TodoSort, last model.Todo) string {
+	cursor := todoCursor{Sort: sort.String(), ID: last.ID}
+	for _, key := range sort {
+		switch key.Field {
+		case "title":
+			cursor.Title = last.Title
+		case "status":
+			cursor.Status = last.Status
+		case "priority":
+			cursor.Priority = string(last.Priority)
+		case "created_at":
+			cursor.CreatedAt = &last.CreatedAt
+		case "updated_at":
+			cursor.UpdatedAt = &last.UpdatedAt
+		}
+	}
+	return cursor.encode()
+}
+
+// decodeCursor はカーソルを解釈して直前のページ末尾のItemを復元する
+// カーソル生成時と並び替え条件が異なる場合はエラーとする
+func decodeCursor(sort model.TodoSort, value string) (*model.Todo, error) {
+	cursor, err := parseCursor(sort.String(), value)
+	if err != nil {
+		return nil, err
+	}
+	last := model.Todo{
+		ID:       cursor.ID,
+		Title:    cursor.Title,
+		Status:   cursor.Status,
+		Priority: model.Priority(cursor.Priority),
+	}
+	if cursor.CreatedAt != nil {
+		last.CreatedAt = *cursor.CreatedAt
+	}
+	if cursor.UpdatedAt != nil {
+		last.UpdatedAt = *cursor.UpdatedAt
+	}
+	return &last, nil
+}
+
+// encodeScheduleCursor はページ末尾のItemから期限の近い順のTodoリストの次のページのカーソルを生成する
+func encodeScheduleCursor(last model.Todo) string {
+	return todoCursor{Sort: scheduleCursorSort, ID: last.ID, DueAt: last.DueAt}.encode()
+}
+
+// decodeScheduleCursor は期限の近い順のTodoリストのカーソルを解釈して直前のページ末尾のItemを復元する
+func decodeScheduleCursor(value string) (*model.Todo, error) {
+	cursor, err := parseCursor(scheduleCursorSort, value)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.DueAt == nil {
+		return nil, fmt.Errorf("cursor has no due_at")
+	}
+	return &model.Todo{ID: cursor.ID, DueAt: cursor.DueAt}, nil
+}
+
+// parseTodoPage はクエリパラメータからTodoリストのページ指定を生成する
+// 不正なパラメータがある場合はそのパラメータ名の一覧を返却する
+func parseTodoPage(c *gin.Context) (model.TodoPage, []string) {
+	page := model.TodoPage{Limit: defaultPageLimit}
+	invalid := []string{}
+
+	if raw, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			invalid = append(invalid, "limit")
+		}
+		page.Limit = limit
+	}
+	sort, err := model.ParseTodoSort(c.Query("sort"))
+	if err != nil {
+		invalid = append(invalid, "sort")
+		return page, invalid
+	}
+	page.Sort = sort
+	if raw, ok := c.GetQuery("cursor"); ok {
+		after, err := decodeCursor(sort, raw)
+		if err != nil {
+			invalid = append(invalid, "cursor")
+		}
+		page.After = after
+	}
+	return page, invalid
+}
+
+// setNextLink は次のページのURLを Link ヘッダに設定する
+func setNextLink(c *gin.Context, cursor string) {
+	next := *c.Request.URL
+	query := next.Query()
+	query.Set("cursor", cursor)
+	next.RawQuery = query.Encode()
+	c.Header("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/lib/repository"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// Project APIのプロジェクトのレスポンスの構造体
+type Project struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProjectPayload プロジェクトの作成の際のPayload
+// color を省略した場合は model.DefaultProjectColor とする
+type ProjectPayload struct {
+	Name     string `json:"name" binding:"required"`
+	Color    string `json:"color"`
+	Archived bool   `json:"archived"`
+}
+
+// ProjectUpdatePayload プロジェクトの部分更新の際のPayload
+// 省略した項目は変更しない
+type ProjectUpdatePayload struct {
+	Name     *string `json:"name"`
+	Color    *string `json:"color"`
+	Archived *bool   `json:"archived"`
+}
+
+func toProjectResponse(project model.Project) Project {
+	return Project{
+		ID:        int(project.ID),
+		Name:      project.Name,
+		Color:     project.Color,
+		Archived:  project.Archived,
+		CreatedAt: project.CreatedAt,
+		UpdatedAt: project.UpdatedAt,
+	}
+}
+
+// writeProjectNameTaken はプロジェクト名の重複のエラーをレスポンスに書き込む
+// エラーを書き込んだ場合はtrueを返却する
+func writeProjectNameTaken(c *gin.Context, err error) bool {
+	if !errors.Is(err, repository.ErrProjectNameTaken) {
+		return false
+	}
+	c.IndentedJSON(http.StatusConflict, gin.H{"message": "Project name is already taken"})
+	return true
+}
+
+// GetProjectList ではログインユーザーのプロジェクトの一覧を名前順で取得する
+// archived=true の場合はアーカイブ済みのプロジェクトのみを取得する
+func (h *Handler) GetProjectList(c *gin.Context) {
+	archived, err := strconv.ParseBool(c.DefaultQuery("archived", "false"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{
+			"message":        "Bad Request: invalid query parameters",
+			"invalid_params": []string{"archived"},
+		})
+		return
+	}
+
+	projects, err := h.projects.GetProjectList(c.Request.Context(), middleware.GetLoginUser(c).ID, archived)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Project not found"})
+		return
+	}
+	result := []Project{}
+	for _, v := range projects {
+		result = append(result, toProjectResponse(v))
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// GetProjectByID ではIDで指定されたプロジェクトを取得する
+func (h *Handler) GetProjectByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+
+	project, err := h.projects.GetProjectByID(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target project is not found"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Target project is not found"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toProjectResponse(project))
+}
+
+// GetProjectTodoList ではIDで指定されたプロジェクトのTODOリストを取得する
+// 絞り込み条件及びページングは GET /todo と同じクエリパラメータで指定する
+func (h *Handler) GetProjectTodoList(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+
+	project, err := h.projects.GetProjectByID(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target project is not found"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Target project is not found"})
+		return
+	}
+	h.writeTodoList(c, func(filter *model.TodoFilter) {
+		filter.ProjectID = &project.ID
+	})
+}
+
+// CreateProject ではログインユーザーのプロジェクトを作成する
+func (h *Handler) CreateProject(c *gin.Context) {
+	var payload ProjectPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+	name, err := model.NormalizeProjectName(payload.Name)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()})
+		return
+	}
+	color := model.DefaultProjectColor
+	if payload.Color != "" {
+		if color, err = model.NormalizeProjectColor(payload.Color); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()})
+			return
+		}
+	}
+
+	newProject, err := h.projects.AddNewProject(
+		c.Request.Context(),
+		middleware.GetLoginUser(c).ID,
+		model.ProjectPayload{
+			Name:     name,
+			Color:    color,
+			Archived: payload.Archived,
+		})
+	if writeProjectNameTaken(c, err) {
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to create project"})
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, toProjectResponse(newProject))
+}
+
+// UpdateProject ではIDで指定されたプロジェクトの名前、色及びアーカイブの状態を更新する
+func (h *Handler) UpdateProject(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+	var payload ProjectUpdatePayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+	update := model.ProjectUpdate{Archived: payload.Archived}
+	if payload.Name != nil {
+		name, err := model.NormalizeProjectName(*payload.Name)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()})
+			return
+		}
+		update.Name = &name
+	}
+	if payload.Color != nil {
+		color, err := model.NormalizeProjectColor(*payload.Color)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()})
+			return
+		}
+		update.Color = &color
+	}
+
+	updated, err := h.projects.UpdateProject(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id), update)
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target project is not found"})
+		return
+	}
+	if writeProjectNameTaken(c, err) {
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to update project"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toProjectResponse(updated))
+}
+
+// DeleteProject ではIDで指定されたプロジェクトを削除する
+// プロジェクトのTODOは既定ではどのプロジェクトにも属さないTODOに戻し、todos=delete の場合は併せて削除する
+func (h *Handler) DeleteProject(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+	var deleteTodos bool
+	switch c.DefaultQuery("todos", "keep") {
+	case "keep":
+	case "delete":
+		deleteTodos = true
+	default:
+		c.IndentedJSON(http.StatusBadRequest, gin.H{
+			"message":        "Bad Request: invalid query parameters",
+			"invalid_params": []string{"todos"},
+		})
+		return
+	}
+
+	deleted, err := h.projects.DeleteProject(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id), deleteTodos)
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target project is not found"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to delete project"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toProjectResponse(deleted))
+}
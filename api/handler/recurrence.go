@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+const (
+	// defaultOccurrenceCount はcount未指定時に返却する繰り返しの日時の件数
+	defaultOccurrenceCount = 5
+	// maxOccurrenceCount はcountに指定できる最大の件数
+	maxOccurrenceCount = 100
+)
+
+// Occurrence APIの繰り返しのTODOの次以降の日時のレスポンスの構造体
+type Occurrence struct {
+	StartAt *time.Time `json:"start_at"`
+	DueAt   time.Time  `json:"due_at"`
+}
+
+// parsePayloadRecurrence はPayloadの繰り返しのルールを検証し、loc のタイムゾーンでの期限の曜日及び日を補った形式に揃える
+// 省略された場合はnilを返却する。不正な値の場合は400のエラーを返却する
+func parsePayloadRecurrence(value *string, dueAt *time.Time, loc *time.Location) (*string, *apiError) {
+	if value == nil || *value == "" {
+		return value, nil
+	}
+	rule, err := model.ParseRecurrence(*value)
+	if err != nil {
+		return nil, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()}}
+	}
+	if dueAt == nil {
+		return nil, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: recurrence requires due_at"}}
+	}
+	normalized := rule.Anchor(dueAt.In(loc)).String()
+	return &normalized, nil
+}
+
+// GetTodoOccurrences ではIDで指定された繰り返しのItemの、期限より後の繰り返しの日時をcount件まで取得する
+// 繰り返さないItemの場合は空のリストを返却する
+func (h *Handler) GetTodoOccurrences(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: id"})
+		return
+	}
+	count := defaultOccurrenceCount
+	if raw, ok := c.GetQuery("count"); ok {
+		count, err = strconv.Atoi(raw)
+		if err != nil || count < 1 || count > maxOccurrenceCount {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{
+				"message":        "Bad Request: invalid query parameters",
+				"invalid_params": []string{"count"},
+			})
+			return
+		}
+	}
+
+	item, err := h.todos.GetTodoItemByID(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target item is not found"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Target item is not found"})
+		return
+	}
+	result := []Occurrence{}
+	if item.Recurrence == "" || item.DueAt == nil {
+		c.IndentedJSON(http.StatusOK, result)
+		return
+	}
+	rule, err := model.ParseRecurrence(item.Recurrence)
+	if err != nil {
+		c.IndentedJSON(http.StatusOK, result)
+		return
+	}
+	for _, dueAt := range rule.Occurrences(item.DueAt.In(item.Location()), count) {
+		occurrence := Occurrence{DueAt: dueAt}
+		if item.StartAt != nil {
+			startAt := item.StartAt.Add(dueAt.Sub(*item.DueAt))
+			occurrence.StartAt = &startAt
+		}
+		result = append(result, occurrence)
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// StopTodoRecurrence ではIDで指定されたItemの繰り返しを終了する
+// 以降はItemを完了しても次のItemを作成しない
+func (h *Handler) StopTodoRecurrence(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+
+	updated, err := h.todos.StopRecurrence(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target item is not found"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to stop recurrence"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toTodoResponse(updated))
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+const (
+	// defaultUpcomingWithin はwithin未指定時に期限が近いとみなす期間
+	defaultUpcomingWithin = 24 * time.Hour
+	// maxUpcomingWithin はwithinに指定できる最大の期間
+	maxUpcomingWithin = 366 * 24 * time.Hour
+)
+
+// dateLayout は日付のみで日時を指定する場合の形式
+const dateLayout = "2006-01-02"
+
+// parseScheduleTime はRFC3339形式の日時または日付をUTCの日時に変換する
+// 日付のみの場合は指定のタイムゾーンでの、endOfDayがtrueならその日の終わり、falseならその日の始まりとする
+func parseScheduleTime(value string, loc *time.Location, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.ParseInLocation(dateLayout, value, loc)
+		if err != nil {
+			return nil, err
+		}
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// parsePayloadTimezone はPayloadのタイムゾーンを読み込む
+// 省略された場合はUTCとし、不正な値の場合は400のエラーを返却する
+func parsePayloadTimezone(value string) (*time.Location, *apiError) {
+	if value == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(value)
+	if err != nil {
+		return nil, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: timezone"}}
+	}
+	return loc, nil
+}
+
+// parsePayloadSchedule はPayloadの開始日時と期限を変換する
+// 日付のみの場合は loc のタイムゾーンで解釈し、不正な値の場合は400のエラーを返却する
+func parsePayloadSchedule(payload Payload, loc *time.Location) (*time.Time, *time.Time, *apiError) {
+	startAt, err := parseScheduleTime(payload.StartAt, loc, false)
+	if err != nil {
+		return nil, nil, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: start_at"}}
+	}
+	dueAt, err := parseScheduleTime(payload.DueAt, loc, true)
+	if err != nil {
+		return nil, nil, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: due_at"}}
+	}
+	if startAt != nil && dueAt != nil && dueAt.Before(*startAt) {
+		return nil, nil, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: due_at must not be before start_at"}}
+	}
+	return startAt, dueAt, nil
+}
+
+// getScheduledTodoList は期限が指定の範囲にある未完了のTODOを期限の近い順に返却する
+// 続きがある場合は GET /todo と同様に次のページのURLを Link ヘッダに設定する
+func (h *Handler) getScheduledTodoList(c *gin.Context, dueAfter, dueBefore *time.Time, invalid []string) {
+	page := model.SchedulePage{Limit: defaultPageLimit}
+	if raw, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			invalid = append(invalid, "limit")
+		}
+		page.Limit = limit
+	}
+	if raw, ok := c.GetQuery("cursor"); ok {
+		after, err := decodeScheduleCursor(raw)
+		if err != nil {
+			invalid = append(invalid, "cursor")
+		}
+		page.After = after
+	}
+	if len(invalid) > 0 {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{
+			"message":        "Bad Request: invalid query parameters",
+			"invalid_params": invalid,
+		})
+		return
+	}
+
+	userID := middleware.GetLoginUser(c).ID
+	workflow, err := h.workflows.GetWorkflow(c.Request.Context(), userID)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Todo List Item not found"})
+		return
+	}
+	// Note: 次のページの有無を判定するため1件多く取得する
+	limit := page.Limit
+	page.Limit++
+	todoList, err := h.todos.GetScheduledTodoList(c.Request.Context(), userID, model.TodoFilter{
+		DueAfter:        dueAfter,
+		DueBefore:       dueBefore,
+		ExcludeStatuses: workflow.FinishedStates(),
+	}, page)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Todo List Item not found"})
+		return
+	}
+	if len(todoList) > limit {
+		todoList = todoList[:limit]
+		setNextLink(c, encodeScheduleCursor(todoList[limit-1]))
+	}
+	result := []Todo{}
+	for _, v := range todoList {
+		result = append(result, toTodoResponse(v))
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// GetOverdueTodoList はGETで期限を過ぎた未完了のTODOを返却する
+func (h *Handler) GetOverdueTodoList(c *gin.Context) {
+	now := time.Now().UTC()
+	h.getScheduledTodoList(c, nil, &now, []string{})
+}
+
+// GetUpcomingTodoList はGETで期限が within (既定は24時間) 以内に迫った未完了のTODOを返却する
+func (h *Handler) GetUpcomingTodoList(c *gin.Context) {
+	invalid := []string{}
+	within := defaultUpcomingWithin
+	if raw, ok := c.GetQuery("within"); ok {
+		var err error
+		within, err = time.ParseDuration(raw)
+		if err != nil || within <= 0 || within > maxUpcomingWithin {
+			invalid = append(invalid, "within")
+		}
+	}
+	now := time.Now().UTC()
+	until := now.Add(within)
+	h.getScheduledTodoList(c, &now, &until, invalid)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// defaultSearchLimit はlimit未指定時に返却する検索結果の件数
+const defaultSearchLimit = 20
+
+// SearchResult 全文検索APIのレスポンスの構造体
+// 抜粋はHTMLエスケープされ、一致箇所は <mark> と </mark> で囲まれる
+type SearchResult struct {
+	Todo
+	Rank           float64 `json:"rank"`
+	TitleSnippet   string  `json:"title_snippet"`
+	DetailsSnippet string  `json:"details_snippet"`
+}
+
+func toSearchResultResponse(result model.TodoSearchResult) SearchResult {
+	return SearchResult{
+		Todo:           toTodoResponse(result.Todo),
+		Rank:           result.Rank,
+		TitleSnippet:   result.TitleSnippet,
+		DetailsSnippet: result.DetailsSnippet,
+	}
+}
+
+// SearchTodo はGETでTitleとDetailsからTODOを検索し、関連度の高い順に返却する
+func (h *Handler) SearchTodo(c *gin.Context) {
+	invalid := []string{}
+	query := c.Query("q")
+	if strings.TrimSpace(query) == "" {
+		invalid = append(invalid, "q")
+	}
+	limit := defaultSearchLimit
+	if raw, ok := c.GetQuery("limit"); ok {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			invalid = append(invalid, "limit")
+		}
+	}
+	if len(invalid) > 0 {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{
+			"message":        "Bad Request: invalid query parameters",
+			"invalid_params": invalid,
+		})
+		return
+	}
+
+	results, err := h.todos.SearchTodo(c.Request.Context(), middleware.GetLoginUser(c).ID, query, limit)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Todo List Item not found"})
+		return
+	}
+	response := []SearchResult{}
+	for _, v := range results {
+		response = append(response, toSearchResultResponse(v))
+	}
+	c.IndentedJSON(http.StatusOK, response)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// TodoNode APIの入れ子にしたTODOリストの要素の構造体
+type TodoNode struct {
+	Todo
+	Children []TodoNode `json:"children"`
+}
+
+// parseTreeMode はクエリパラメータから入れ子のTODOリストを返却するかを取得する
+func parseTreeMode(c *gin.Context) (bool, []string) {
+	tree, err := strconv.ParseBool(c.DefaultQuery("tree", "false"))
+	if err != nil {
+		return false, []string{"tree"}
+	}
+	return tree, []string{}
+}
+
+// buildTodoTree はTODOリストを親子関係に従って入れ子にする
+// 親がリストに含まれないItemは最上位とし、兄弟の順序はリストの順序に従う
+// ページに分割した場合、親が前のページに含まれるItemは次のページの最上位となる
+func buildTodoTree(todoList model.TodoList) []TodoNode {
+	included := map[uint]bool{}
+	for _, todo := range todoList {
+		included[todo.ID] = true
+	}
+	children := map[uint]model.TodoList{}
+	roots := model.TodoList{}
+	for _, todo := range todoList {
+		if todo.ParentID != nil && included[*todo.ParentID] {
+			children[*todo.ParentID] = append(children[*todo.ParentID], todo)
+			continue
+		}
+		roots = append(roots, todo)
+	}
+	var build func(todoList model.TodoList) []TodoNode
+	build = func(todoList model.TodoList) []TodoNode {
+		nodes := []TodoNode{}
+		for _, todo := range todoList {
+			nodes = append(nodes, TodoNode{Todo: toTodoResponse(todo), Children: build(children[todo.ID])})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+// GetTodoChildren ではIDで指定されたItemの子のTODOリストを取得する
+// 絞り込み条件及びページングは GET /todo と同じクエリパラメータで指定する
+func (h *Handler) GetTodoChildren(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: id"})
+		return
+	}
+
+	parent, err := h.todos.GetTodoItemByID(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target item is not found"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Target item is not found"})
+		return
+	}
+	h.writeTodoList(c, func(filter *model.TodoFilter) {
+		filter.ParentID = &parent.ID
+	})
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/lib/repository"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// Tag APIのタグのレスポンスの構造体
+// Countは一覧取得時のみ返却する
+type Tag struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Count     *int      `json:"count,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagPayload タグの作成及び名前変更の際のPayload
+type TagPayload struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func toTagResponse(tag model.Tag) Tag {
+	return Tag{
+		ID:        int(tag.ID),
+		Name:      tag.Name,
+		CreatedAt: tag.CreatedAt,
+	}
+}
+
+// bindTagName はPayloadからタグ名を取得する
+// 不正な値の場合は400を返却し、falseを返却する
+func bindTagName(c *gin.Context) (string, bool) {
+	var payload TagPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return "", false
+	}
+	name, err := model.NormalizeTagName(payload.Name)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()})
+		return "", false
+	}
+	return name, true
+}
+
+// GetTagList ではログインユーザーのタグの一覧を、各タグが設定されたTODOの件数と共に取得する
+func (h *Handler) GetTagList(c *gin.Context) {
+	tags, err := h.tags.GetTagList(c.Request.Context(), middleware.GetLoginUser(c).ID)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Tag not found"})
+		return
+	}
+	result := []Tag{}
+	for _, v := range tags {
+		item := toTagResponse(v.Tag)
+		count := v.Count
+		item.Count = &count
+		result = append(result, item)
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// CreateTag ではログインユーザーのタグを作成する
+func (h *Handler) CreateTag(c *gin.Context) {
+	name, ok := bindTagName(c)
+	if !ok {
+		return
+	}
+
+	newTag, err := h.tags.AddNewTag(c.Request.Context(), middleware.GetLoginUser(c).ID, name)
+	if errors.Is(err, repository.ErrTagNameTaken) {
+		c.IndentedJSON(http.StatusConflict, gin.H{"message": "Tag name is already taken"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to create tag"})
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, toTagResponse(newTag))
+}
+
+// RenameTag ではIDで指定されたタグの名前を変更する
+// 変更はタグが設定された全てのTODOに反映される
+func (h *Handler) RenameTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+	name, ok := bindTagName(c)
+	if !ok {
+		return
+	}
+
+	renamed, err := h.tags.RenameTag(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id), name)
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target tag is not found"})
+		return
+	}
+	if errors.Is(err, repository.ErrTagNameTaken) {
+		c.IndentedJSON(http.StatusConflict, gin.H{"message": "Tag name is already taken"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to rename tag"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toTagResponse(renamed))
+}
+
+// DeleteTag ではIDで指定されたタグを削除し、全てのTODOから外す
+func (h *Handler) DeleteTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+
+	deleted, err := h.tags.DeleteTag(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target tag is not found"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to delete tag"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toTagResponse(deleted))
+}
@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,94 +9,307 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/Z-me/practice-todo-api/api/model"
-	"github.com/Z-me/practice-todo-api/lib/db"
-	"github.com/Z-me/practice-todo-api/lib/util"
+	"github.com/Z-me/practice-todo-api/lib/repository"
+	"github.com/Z-me/practice-todo-api/middleware"
 )
 
 // Todo APIのレスポンスの構造体
 type Todo struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title" binding:"required,max=30"`
-	Status    string    `json:"status" binding:"required"`
-	Details   string    `json:"details"`
-	Priority  string    `json:"priority" binding:"required,max=1000"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int        `json:"id"`
+	ProjectID   *int       `json:"project_id"`
+	ParentID    *int       `json:"parent_id"`
+	Title       string     `json:"title" binding:"required,max=30"`
+	Status      string     `json:"status" binding:"required"`
+	Details     string     `json:"details"`
+	Priority    string     `json:"priority" binding:"required"`
+	StartAt     *time.Time `json:"start_at"`
+	DueAt       *time.Time `json:"due_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	Recurrence  *string    `json:"recurrence"`
+	// Timezone は繰り返しのルールを計算するタイムゾーンで、繰り返さない場合はnull
+	Timezone   *string    `json:"timezone"`
+	ArchivedAt *time.Time `json:"archived_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	// DeletedAt はゴミ箱に移動した日時で、ゴミ箱のTODO以外はnull
+	DeletedAt *time.Time `json:"deleted_at"`
+	Tags      []string   `json:"tags"`
+	// Progress は子のTODOの完了率 (%) で、子を持たない場合はnull
+	Progress *int `json:"progress"`
+	// BlockedBy はこのTODOが終了を待つTODOのID、Blocking はこのTODOの終了を待つTODOのID
+	BlockedBy []int `json:"blocked_by"`
+	Blocking  []int `json:"blocking"`
+}
+
+// toOptionalID は省略可能なIDをレスポンスの値に変換する
+func toOptionalID(id *uint) *int {
+	if id == nil {
+		return nil
+	}
+	v := int(*id)
+	return &v
+}
+
+// toIDs はIDの一覧をレスポンスの値に変換する
+func toIDs(ids []uint) []int {
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, int(id))
+	}
+	return result
+}
+
+func toTodoResponse(todo model.Todo) Todo {
+	var recurrence, timezone *string
+	if todo.Recurrence != "" {
+		recurrence = &todo.Recurrence
+		name := todo.Location().String()
+		timezone = &name
+	}
+	var deletedAt *time.Time
+	if todo.DeletedAt.Valid {
+		deletedAt = &todo.DeletedAt.Time
+	}
+	return Todo{
+		ID:          int(todo.ID),
+		ProjectID:   toOptionalID(todo.ProjectID),
+		ParentID:    toOptionalID(todo.ParentID),
+		Title:       todo.Title,
+		Status:      todo.Status,
+		Details:     todo.Details,
+		Priority:    string(todo.Priority),
+		StartAt:     todo.StartAt,
+		DueAt:       todo.DueAt,
+		StartedAt:   todo.StartedAt,
+		CompletedAt: todo.CompletedAt,
+		Recurrence:  recurrence,
+		Timezone:    timezone,
+		ArchivedAt:  todo.ArchivedAt,
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
+		DeletedAt:   deletedAt,
+		Tags:        todo.Tags,
+		Progress:    todo.Progress,
+		BlockedBy:   toIDs(todo.BlockedBy),
+		Blocking:    toIDs(todo.Blocking),
+	}
 }
 
 // Payload APIのDBの新規作成及び更新のPayload
+// start_at と due_at はRFC3339形式の日時か、timezone のタイムゾーンでの日付 (YYYY-MM-DD) で指定する
+// recurrence を指定した場合、繰り返しのルールも timezone のタイムゾーンで計算する
 type Payload struct {
 	Title    string `json:"title" binding:"required,max=30"`
 	Status   string `json:"status" binding:"required"`
 	Details  string `json:"details"`
-	Priority string `json:"priority" binding:"required,max=1000"`
+	Priority string `json:"priority" binding:"required"`
+	StartAt  string `json:"start_at"`
+	DueAt    string `json:"due_at"`
+	Timezone string `json:"timezone"`
+	// Tags を省略した場合、更新時はタグを変更しない
+	Tags []string `json:"tags"`
+	// ProjectID を省略した場合、更新時はプロジェクトを変更しない。0でプロジェクトから外す
+	ProjectID *uint `json:"project_id"`
+	// ParentID を省略した場合、更新時は親を変更しない。0で親から外す
+	ParentID *uint `json:"parent_id"`
+	// Recurrence は "FREQ=WEEKLY;BYDAY=MO" のようなRRULEで、期限が必要。省略した場合、更新時は繰り返しを変更しない。空文字で繰り返しを外す
+	Recurrence *string `json:"recurrence"`
+	// Archived を省略した場合、更新時はアーカイブの状態を変更しない。trueでアーカイブし、既定の一覧に含めない
+	Archived *bool `json:"archived"`
+	// CompleteChildren がtrueの場合、完了にする際に未完了の子孫も完了にする。falseの場合は未完了の子孫があれば409を返却する
+	CompleteChildren bool `json:"complete_children"`
 }
 
 // StatusPayload APIのStatusのみ更新する際のPayload
 type StatusPayload struct {
-	Status string `json:"status" binding:"required"`
+	Status           string `json:"status" binding:"required"`
+	CompleteChildren bool   `json:"complete_children"`
 }
 
-func connectDB(c *gin.Context) {
-	if err := util.ConnectDB(); err != nil {
-		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "failed to connect database"})
+// parsePayloadPriority はPayloadのPriorityを変換する
+// 不正な値の場合は有効な値の一覧と共に400のエラーを返却する
+func parsePayloadPriority(value string) (model.Priority, *apiError) {
+	priority, err := model.ParsePriority(value)
+	if err != nil {
+		return "", &apiError{http.StatusBadRequest, gin.H{
+			"message":          "Bad Request: unknown priority " + value,
+			"valid_priorities": model.Priorities(),
+		}}
 	}
+	return priority, nil
+}
+
+// parsePayloadTags はPayloadのタグ名を検証し、重複を除く
+// 省略された場合はnilを返却する。不正な値の場合は400のエラーを返却する
+func parsePayloadTags(names []string) ([]string, *apiError) {
+	if names == nil {
+		return nil, nil
+	}
+	tags, err := model.NormalizeTagNames(names)
+	if err != nil {
+		return nil, &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()}}
+	}
+	return tags, nil
+}
+
+// parsePayload はPayloadを検証してItemの作成及び更新の値に変換する
+func parsePayload(payload Payload) (model.Payload, *apiError) {
+	priority, apiErr := parsePayloadPriority(payload.Priority)
+	if apiErr != nil {
+		return model.Payload{}, apiErr
+	}
+	loc, apiErr := parsePayloadTimezone(payload.Timezone)
+	if apiErr != nil {
+		return model.Payload{}, apiErr
+	}
+	startAt, dueAt, apiErr := parsePayloadSchedule(payload, loc)
+	if apiErr != nil {
+		return model.Payload{}, apiErr
+	}
+	tags, apiErr := parsePayloadTags(payload.Tags)
+	if apiErr != nil {
+		return model.Payload{}, apiErr
+	}
+	recurrence, apiErr := parsePayloadRecurrence(payload.Recurrence, dueAt, loc)
+	if apiErr != nil {
+		return model.Payload{}, apiErr
+	}
+	return model.Payload{
+		Title:            payload.Title,
+		Status:           payload.Status,
+		Details:          payload.Details,
+		Priority:         priority,
+		StartAt:          startAt,
+		DueAt:            dueAt,
+		Tags:             tags,
+		ProjectID:        payload.ProjectID,
+		ParentID:         payload.ParentID,
+		Recurrence:       recurrence,
+		Timezone:         loc.String(),
+		Archived:         payload.Archived,
+		CompleteChildren: payload.CompleteChildren,
+	}, nil
+}
+
+// writeReferenceError はTodoに指定したプロジェクト、親またはBlockerが不正なエラーをレスポンスに書き込む
+// エラーを書き込んだ場合はtrueを返却する
+func writeReferenceError(c *gin.Context, err error) bool {
+	if apiErr := referenceError(err); apiErr != nil {
+		apiErr.write(c)
+		return true
+	}
+	return false
+}
+
+// referenceError はTodoに指定したプロジェクト、親またはBlockerが不正なエラーを変換する
+// いずれでもない場合はnilを返却する
+func referenceError(err error) *apiError {
+	switch {
+	case errors.Is(err, repository.ErrInvalidProject):
+		return &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: project_id must be an active project"}}
+	case errors.Is(err, repository.ErrInvalidParent):
+		return &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: parent_id must be an existing item"}}
+	case errors.Is(err, repository.ErrParentCycle):
+		return &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: parent_id must not be the item itself or its descendant"}}
+	case errors.Is(err, repository.ErrInvalidBlocker):
+		return &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: blocker_id must be an existing item"}}
+	case errors.Is(err, repository.ErrDependencyCycle):
+		return &apiError{http.StatusBadRequest, gin.H{"message": "Bad Request: blocker_id must not be the item itself or an item waiting for it"}}
+	default:
+		return nil
+	}
+}
+
+// todoError はItemの作成、更新、Statusの変更及び削除のエラーを変換する
+// 対象のItemが存在しない場合は404、ワークフロー及び参照のエラー以外はfallbackのメッセージで400とする
+func todoError(err error, fallback string) *apiError {
+	if isNotFound(err) {
+		return &apiError{http.StatusNotFound, gin.H{"message": "Target item is not found"}}
+	}
+	if apiErr := workflowError(err); apiErr != nil {
+		return apiErr
+	}
+	if apiErr := referenceError(err); apiErr != nil {
+		return apiErr
+	}
+	return &apiError{http.StatusBadRequest, gin.H{"message": fallback}}
 }
 
 // GetTodoList はGETでTODOリストを取得する
-func GetTodoList(c *gin.Context) {
-	connectDB(c)
-	defer util.DisconnectDB()
+// tree=true の場合は親子関係に従って入れ子にしたTODOリストを返却し、ページは入れ子にする前のTODOの件数で分割する
+func (h *Handler) GetTodoList(c *gin.Context) {
+	h.writeTodoList(c, nil)
+}
 
-	dbObj := util.GetDbObj()
-	todoList, err := db.GetTodoList(dbObj)
+// writeTodoList はクエリパラメータの絞り込み条件とページでTODOリストを取得してレスポンスに書き込む
+// scopeを指定した場合はクエリパラメータから生成した絞り込み条件を上書きする
+func (h *Handler) writeTodoList(c *gin.Context, scope func(filter *model.TodoFilter)) {
+	workflow, err := h.workflows.GetWorkflow(c.Request.Context(), middleware.GetLoginUser(c).ID)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Todo List Item not found"})
+		return
+	}
+	filter, invalid := parseTodoFilter(c, workflow)
+	if scope != nil {
+		scope(&filter)
+	}
+	page, invalidPage := parseTodoPage(c)
+	invalid = append(invalid, invalidPage...)
+	tree, invalidTree := parseTreeMode(c)
+	invalid = append(invalid, invalidTree...)
+	if len(invalid) > 0 {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{
+			"message":        "Bad Request: invalid query parameters",
+			"invalid_params": invalid,
+		})
+		return
+	}
+	// Note: 次のページの有無を判定するため1件多く取得する
+	limit := page.Limit
+	page.Limit++
+	todoList, err := h.todos.GetTodoList(c.Request.Context(), middleware.GetLoginUser(c).ID, filter, page)
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Todo List Item not found"})
+		return
+	}
+	if len(todoList) > limit {
+		todoList = todoList[:limit]
+		setNextLink(c, encodeCursor(page.Sort, todoList[limit-1]))
+	}
+	if tree {
+		c.IndentedJSON(http.StatusOK, buildTodoTree(todoList))
+		return
 	}
 	result := []Todo{}
 	for _, v := range todoList {
-		item := Todo{
-			ID:        int(v.ID),
-			Title:     v.Title,
-			Status:    v.Status,
-			Details:   v.Details,
-			Priority:  v.Priority,
-			CreatedAt: v.CreatedAt,
-			UpdatedAt: v.UpdatedAt,
-		}
-		result = append(result, item)
+		result = append(result, toTodoResponse(v))
 	}
 	c.IndentedJSON(http.StatusOK, result)
 }
 
 // GetTodoItemByID ではIDから任意のItemを取得する
-func GetTodoItemByID(c *gin.Context) {
+func (h *Handler) GetTodoItemByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: id"})
+		return
 	}
 
-	connectDB(c)
-	defer util.DisconnectDB()
-	dbObj := util.GetDbObj()
-
-	item, err := db.GetTodoItemByID(dbObj, uint(id))
+	item, err := h.todos.GetTodoItemByID(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target item is not found"})
+		return
+	}
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Target item is not found"})
+		return
 	}
-	c.IndentedJSON(http.StatusOK, Todo{
-		ID:        int(item.ID),
-		Title:     item.Title,
-		Status:    item.Status,
-		Details:   item.Details,
-		Priority:  item.Priority,
-		CreatedAt: item.CreatedAt,
-		UpdatedAt: item.UpdatedAt,
-	})
+	c.IndentedJSON(http.StatusOK, toTodoResponse(item))
 }
 
 // AddNewTodo では、POSTでItemを追加する
-func AddNewTodo(c *gin.Context) {
+func (h *Handler) AddNewTodo(c *gin.Context) {
 	var payload Payload
 
 	if err := c.BindJSON(&payload); err != nil {
@@ -104,35 +317,25 @@ func AddNewTodo(c *gin.Context) {
 		return
 	}
 
-	connectDB(c)
-	defer util.DisconnectDB()
-	dbObj := util.GetDbObj()
+	parsed, apiErr := parsePayload(payload)
+	if apiErr != nil {
+		apiErr.write(c)
+		return
+	}
 
-	newTodo, err := db.AddNewTodo(
-		dbObj,
-		model.Payload{
-			Title:    payload.Title,
-			Status:   payload.Status,
-			Details:  payload.Details,
-			Priority: payload.Priority,
-		})
+	newTodo, err := h.todos.AddNewTodo(c.Request.Context(), middleware.GetLoginUser(c).ID, parsed)
+	if writeWorkflowError(c, err) || writeReferenceError(c, err) {
+		return
+	}
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to create new item"})
 		return
 	}
-	c.IndentedJSON(http.StatusCreated, Todo{
-		ID:        int(newTodo.ID),
-		Title:     newTodo.Title,
-		Status:    newTodo.Status,
-		Details:   newTodo.Details,
-		Priority:  newTodo.Priority,
-		CreatedAt: newTodo.CreatedAt,
-		UpdatedAt: newTodo.UpdatedAt,
-	})
+	c.IndentedJSON(http.StatusCreated, toTodoResponse(newTodo))
 }
 
 // UpdateTodoItem ではIDで指定されたItemを更新する
-func UpdateTodoItem(c *gin.Context) {
+func (h *Handler) UpdateTodoItem(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
@@ -145,37 +348,29 @@ func UpdateTodoItem(c *gin.Context) {
 		return
 	}
 
-	connectDB(c)
-	defer util.DisconnectDB()
-
-	dbObj := util.GetDbObj()
+	parsed, apiErr := parsePayload(payload)
+	if apiErr != nil {
+		apiErr.write(c)
+		return
+	}
 
-	updated, err := db.UpdateItem(
-		dbObj,
-		uint(id),
-		model.Payload{
-			Title:    payload.Title,
-			Status:   payload.Status,
-			Details:  payload.Details,
-			Priority: payload.Priority,
-		})
+	updated, err := h.todos.UpdateItem(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id), parsed)
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target item is not found"})
+		return
+	}
+	if writeWorkflowError(c, err) || writeReferenceError(c, err) {
+		return
+	}
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to update item"})
+		return
 	}
-	fmt.Println("updated", updated)
-	c.IndentedJSON(http.StatusOK, Todo{
-		ID:        id,
-		Title:     updated.Title,
-		Status:    updated.Status,
-		Details:   updated.Details,
-		Priority:  updated.Priority,
-		CreatedAt: updated.CreatedAt,
-		UpdatedAt: updated.UpdatedAt,
-	})
+	c.IndentedJSON(http.StatusOK, toTodoResponse(updated))
 }
 
 // UpdateTodoState ではIDを指定したITEMのStatusを更新する
-func UpdateTodoState(c *gin.Context) {
+func (h *Handler) UpdateTodoState(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
@@ -188,38 +383,34 @@ func UpdateTodoState(c *gin.Context) {
 		return
 	}
 
-	connectDB(c)
-	defer util.DisconnectDB()
-	dbObj := util.GetDbObj()
-
-	updated, err := db.UpdateItemStatus(
-		dbObj,
+	updated, err := h.todos.UpdateItemStatus(
+		c.Request.Context(),
+		middleware.GetLoginUser(c).ID,
 		uint(id),
 		model.Status{
-			Status: payload.Status,
+			Status:           payload.Status,
+			CompleteChildren: payload.CompleteChildren,
 		})
 	if err != nil {
-		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to update item"})
+		todoError(err, "fail to update item").write(c)
+		return
 	}
-	c.IndentedJSON(http.StatusOK, updated)
+	c.IndentedJSON(http.StatusOK, toTodoResponse(updated))
 }
 
-// DeleteTodoListItem ではIDで指定されたItemを削除する
-func DeleteTodoListItem(c *gin.Context) {
+// DeleteTodoListItem ではIDで指定されたItemをゴミ箱に移動する
+func (h *Handler) DeleteTodoListItem(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
 		return
 	}
 
-	connectDB(c)
-	defer util.DisconnectDB()
-	dbObj := util.GetDbObj()
-
-	deleted, err := db.DeleteItem(dbObj, uint(id))
+	deleted, err := h.todos.DeleteItem(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
 	if err != nil {
-		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to update item"})
+		todoError(err, "fail to update item").write(c)
+		return
 	}
 
-	c.IndentedJSON(http.StatusOK, deleted)
+	c.IndentedJSON(http.StatusOK, toTodoResponse(deleted))
 }
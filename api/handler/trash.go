@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// GetTrashList ではログインユーザーのゴミ箱のItemを削除日時の新しい順で取得する
+func (h *Handler) GetTrashList(c *gin.Context) {
+	todoList, err := h.todos.GetTrashList(c.Request.Context(), middleware.GetLoginUser(c).ID)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Trash not found"})
+		return
+	}
+	result := []Todo{}
+	for _, v := range todoList {
+		result = append(result, toTodoResponse(v))
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// RestoreTodoItem ではIDで指定されたゴミ箱のItemを元に戻す
+func (h *Handler) RestoreTodoItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+
+	restored, err := h.todos.RestoreItem(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target item is not found in trash"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to restore item"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toTodoResponse(restored))
+}
+
+// PurgeTrashItem ではIDで指定されたゴミ箱のItemを完全に削除する
+func (h *Handler) PurgeTrashItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: ID"})
+		return
+	}
+
+	purged, err := h.todos.PurgeItem(c.Request.Context(), middleware.GetLoginUser(c).ID, uint(id))
+	if isNotFound(err) {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "Target item is not found in trash"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to purge item"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toTodoResponse(purged))
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/lib/repository"
+	"github.com/Z-me/practice-todo-api/lib/util"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// User APIのユーザー情報のレスポンスの構造体
+type User struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserPayload ユーザー登録の際のPayload
+type UserPayload struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	Password string `json:"password" binding:"required"`
+}
+
+// UpdateUserPayload ユーザー情報を更新する際のPayload
+// パスワードを変更する場合は現在のパスワードも必要
+type UpdateUserPayload struct {
+	Name            string `json:"name" binding:"max=50"`
+	Password        string `json:"password"`
+	CurrentPassword string `json:"current_password"`
+}
+
+func toUserResponse(user model.User) User {
+	return User{
+		ID:        int(user.ID),
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// CreateUser ではユーザーを新規登録する
+func (h *Handler) CreateUser(c *gin.Context) {
+	var payload UserPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+	if err := util.CheckPasswordStrength(payload.Password); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()})
+		return
+	}
+
+	newUser, err := h.users.AddNewUser(c.Request.Context(), model.UserPayload{
+		Name:     payload.Name,
+		Password: payload.Password,
+	})
+	if errors.Is(err, repository.ErrUserNameTaken) {
+		c.IndentedJSON(http.StatusConflict, gin.H{"message": "user name is already taken"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to create new user"})
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, toUserResponse(newUser))
+}
+
+// GetMe ではログインユーザーの情報を取得する
+func (h *Handler) GetMe(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, toUserResponse(middleware.GetLoginUser(c)))
+}
+
+// UpdateMe ではログインユーザーの名前またはパスワードを更新する
+func (h *Handler) UpdateMe(c *gin.Context) {
+	var payload UpdateUserPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+	if payload.Name == "" && payload.Password == "" {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: name or password is required"})
+		return
+	}
+	loginUser := middleware.GetLoginUser(c)
+	if payload.Password != "" {
+		if !util.ComparePassword(loginUser.Password, payload.CurrentPassword) {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: current_password is incorrect"})
+			return
+		}
+		if err := util.CheckPasswordStrength(payload.Password); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()})
+			return
+		}
+	}
+
+	updated, err := h.users.UpdateUser(c.Request.Context(), loginUser.ID, model.UserPayload{
+		Name:     payload.Name,
+		Password: payload.Password,
+	})
+	if errors.Is(err, repository.ErrUserNameTaken) {
+		c.IndentedJSON(http.StatusConflict, gin.H{"message": "user name is already taken"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to update user"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toUserResponse(updated))
+}
+
+// DeleteMe ではログインユーザーをTodoと共に削除する
+// 引き継ぎ先のユーザーの同意を得られないため、他のユーザーへのTodoの引き継ぎ (transfer_to) は受け付けない
+func (h *Handler) DeleteMe(c *gin.Context) {
+	loginUser := middleware.GetLoginUser(c)
+
+	if _, ok := c.GetQuery("transfer_to"); ok {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: transfer_to is not supported"})
+		return
+	}
+
+	if err := h.users.DeleteUser(c.Request.Context(), loginUser.ID); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to delete user"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toUserResponse(loginUser))
+}
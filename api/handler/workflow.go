@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/middleware"
+)
+
+// Workflow ワークフローAPIのレスポンス及びPayloadの構造体
+type Workflow struct {
+	States          []string            `json:"states" binding:"required"`
+	Transitions     map[string][]string `json:"transitions"`
+	StartedStates   []string            `json:"started_states"`
+	CompletedStates []string            `json:"completed_states"`
+	ClosedStates    []string            `json:"closed_states"`
+}
+
+func toWorkflowResponse(workflow model.Workflow) Workflow {
+	return Workflow{
+		States:          workflow.States,
+		Transitions:     workflow.Transitions,
+		StartedStates:   workflow.StartedStates,
+		CompletedStates: workflow.CompletedStates,
+		ClosedStates:    workflow.ClosedStates,
+	}
+}
+
+// GetWorkflow はログインユーザーのワークフローを返却する
+func (h *Handler) GetWorkflow(c *gin.Context) {
+	workflow, err := h.workflows.GetWorkflow(c.Request.Context(), middleware.GetLoginUser(c).ID)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to get workflow"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toWorkflowResponse(workflow))
+}
+
+// UpdateWorkflow はログインユーザーのワークフローを置き換える
+// ワークフローに存在しないStatusのItemは、任意のStatusに遷移できる
+func (h *Handler) UpdateWorkflow(c *gin.Context) {
+	var payload Workflow
+	if err := c.BindJSON(&payload); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: Payload"})
+		return
+	}
+	workflow := model.Workflow{
+		States:          payload.States,
+		Transitions:     payload.Transitions,
+		StartedStates:   payload.StartedStates,
+		CompletedStates: payload.CompletedStates,
+		ClosedStates:    payload.ClosedStates,
+	}
+	if err := workflow.Validate(); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Bad Request: " + err.Error()})
+		return
+	}
+
+	if err := h.workflows.SaveWorkflow(c.Request.Context(), middleware.GetLoginUser(c).ID, workflow); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to update workflow"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toWorkflowResponse(workflow))
+}
+
+// ResetWorkflow はログインユーザーのワークフローを既定のワークフローに戻す
+func (h *Handler) ResetWorkflow(c *gin.Context) {
+	if err := h.workflows.DeleteWorkflow(c.Request.Context(), middleware.GetLoginUser(c).ID); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "fail to reset workflow"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toWorkflowResponse(model.DefaultWorkflow()))
+}
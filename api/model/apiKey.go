@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// APIキーの権限範囲
+const (
+	APIKeyScopeRead      = "read"
+	APIKeyScopeReadWrite = "read_write"
+)
+
+type APIKey struct {
+	ID         uint `gorm:"primaryKey"`
+	UserID     uint
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scope      string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+type APIKeyPayload struct {
+	Name      string
+	Scope     string
+	ExpiresAt *time.Time
+}
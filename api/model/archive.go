@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// SetArchived はTodoのアーカイブの状態を変更する
+// 既に同じ状態の場合はアーカイブした日時を変更しない
+func SetArchived(todo *Todo, archived bool, now time.Time) {
+	switch {
+	case archived && todo.ArchivedAt == nil:
+		todo.ArchivedAt = &now
+	case !archived:
+		todo.ArchivedAt = nil
+	}
+}
+
+// CanArchiveCompleted はTodoがbeforeより前に完了した、アーカイブしていないItemかを判定する
+func (w Workflow) CanArchiveCompleted(todo Todo, before time.Time) bool {
+	return todo.ArchivedAt == nil && w.IsCompleted(todo.Status) &&
+		todo.CompletedAt != nil && todo.CompletedAt.Before(before)
+}
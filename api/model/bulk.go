@@ -0,0 +1,28 @@
+package model
+
+// MaxBulkOperations は1回の一括処理で指定できる操作の最大数
+const MaxBulkOperations = 1000
+
+// 一括処理の操作の種類
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkStatus = "status"
+	BulkDelete = "delete"
+)
+
+// BulkOperation は一括処理の1件の操作
+// ID は create 以外で、Payload は create 及び update で、Status は status で利用する
+type BulkOperation struct {
+	Op      string
+	ID      uint
+	Payload Payload
+	Status  Status
+}
+
+// BulkResult は一括処理の1件の操作の結果
+// 全ての操作を取り消す場合、失敗した操作より後の操作は実行せずゼロ値とする
+type BulkResult struct {
+	Todo Todo
+	Err  error
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TodoDependency はTodoが別のTodo (Blocker) の終了を待つ関係
+type TodoDependency struct {
+	TodoID    uint `gorm:"primaryKey"`
+	BlockerID uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+// DependsOn はTodoの依存関係を辿ってtargetに到達するかを判定する
+// blockersはTodoのID毎のBlockerのIDの一覧
+func DependsOn(blockers map[uint][]uint, id uint, target uint) bool {
+	seen := map[uint]bool{}
+	queue := []uint{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == target {
+			return true
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		queue = append(queue, blockers[current]...)
+	}
+	return false
+}
+
+// OpenBlockers は完了にするTodoのBlockerのうち、終了していないものを返却する
+// 同時に完了にするTodoはBlockerに含めない
+func (w Workflow) OpenBlockers(blockers []Todo, completing []uint) []uint {
+	open := []uint{}
+	for _, blocker := range blockers {
+		if w.IsFinished(blocker.Status) || containsID(completing, blocker.ID) || containsID(open, blocker.ID) {
+			continue
+		}
+		open = append(open, blocker.ID)
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i] < open[j] })
+	return open
+}
+
+func containsID(ids []uint, target uint) bool {
+	for _, id := range ids {
+		if id == target {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenBlockersError は終了していないBlockerを持つTodoを完了しようとした場合のエラー
+type OpenBlockersError struct {
+	IDs []uint
+}
+
+func (e *OpenBlockersError) Error() string {
+	return fmt.Sprintf("todo is blocked by %d open items", len(e.IDs))
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority はTodoの優先度
+// P0 が最も高く P4 が最も低い。並び替えは Rank の値で行うため、降順が優先度の高い順となる
+type Priority string
+
+// Priorityの一覧
+const (
+	PriorityP0 Priority = "P0"
+	PriorityP1 Priority = "P1"
+	PriorityP2 Priority = "P2"
+	PriorityP3 Priority = "P3"
+	PriorityP4 Priority = "P4"
+)
+
+// Priorities は利用できるPriorityを優先度の高い順に返却する
+func Priorities() []Priority {
+	return []Priority{PriorityP0, PriorityP1, PriorityP2, PriorityP3, PriorityP4}
+}
+
+// Rank は並び替えに利用する優先度の高さを返却する
+// P0 が最も大きく、定義されていないPriorityは0とする
+func (p Priority) Rank() int {
+	priorities := Priorities()
+	for i, v := range priorities {
+		if p == v {
+			return len(priorities) - i
+		}
+	}
+	return 0
+}
+
+// ParsePriority は文字列をPriorityに変換する
+// 大文字小文字は区別しない
+func ParsePriority(value string) (Priority, error) {
+	priority := Priority(strings.ToUpper(strings.TrimSpace(value)))
+	for _, p := range Priorities() {
+		if priority == p {
+			return priority, nil
+		}
+	}
+	return "", &PriorityError{Priority: value}
+}
+
+// PriorityError は定義されていないPriorityが指定された場合のエラー
+type PriorityError struct {
+	Priority string
+}
+
+func (e *PriorityError) Error() string {
+	valid := []string{}
+	for _, p := range Priorities() {
+		valid = append(valid, string(p))
+	}
+	return fmt.Sprintf("unknown priority %q: valid priorities are %s", e.Priority, strings.Join(valid, ", "))
+}
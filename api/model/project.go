@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxProjectNameLength はプロジェクト名の最大文字数
+	MaxProjectNameLength = 50
+	// DefaultProjectColor は色を指定せずに作成したプロジェクトの色
+	DefaultProjectColor = "#808080"
+)
+
+var projectColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// Project はTodoをまとめるユーザー毎のリスト
+// アーカイブ済みのプロジェクトには新たにTodoを追加できない
+type Project struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint
+	Name      string
+	Color     string
+	Archived  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ProjectPayload struct {
+	Name     string
+	Color    string
+	Archived bool
+}
+
+// ProjectUpdate はプロジェクトの部分更新の内容で、nilの項目は変更しない
+type ProjectUpdate struct {
+	Name     *string
+	Color    *string
+	Archived *bool
+}
+
+// NormalizeProjectName はプロジェクト名の前後の空白を除去し、利用できる名前かを検証する
+func NormalizeProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxProjectNameLength {
+		return "", fmt.Errorf("invalid project name %q: must be 1 to %d characters", name, MaxProjectNameLength)
+	}
+	return name, nil
+}
+
+// NormalizeProjectColor はプロジェクトの色を小文字の "#rrggbb" 形式に揃えて検証する
+func NormalizeProjectColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if !projectColorPattern.MatchString(color) {
+		return "", fmt.Errorf("invalid project color %q: must be #rrggbb", color)
+	}
+	return color, nil
+}
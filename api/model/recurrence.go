@@ -0,0 +1,351 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// FreqDaily などは繰り返しの単位
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+
+	// MaxRecurrenceLength は繰り返しのルールの最大文字数
+	MaxRecurrenceLength = 255
+	// maxRecurrenceInterval はINTERVALに指定できる最大値
+	maxRecurrenceInterval = 366
+	// maxRecurrenceCount はCOUNTに指定できる最大値
+	maxRecurrenceCount = 1000
+	// maxRecurrencePeriods は次の日時を探す期間の数の上限で、該当する日時がないルールでの無限ループを防ぐ
+	maxRecurrencePeriods = 10000
+)
+
+// untilLayout などはUNTILの日時及び日付の形式
+const (
+	untilLayout     = "20060102T150405Z"
+	untilDateLayout = "20060102"
+)
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Recurrence はRFC 5545のRRULEのうち FREQ (DAILY, WEEKLY, MONTHLY)、INTERVAL、BYDAY、
+// BYMONTHDAY、COUNT、UNTIL に対応する繰り返しのルール
+// 日時は最初の日時 (DTSTART) のタイムゾーンの暦で計算し、最初の日時はTodoの期限とする
+type Recurrence struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	// Count は最初の日時を含む回数で、0の場合は制限しない
+	Count int
+	Until *time.Time
+}
+
+// ParseRecurrence は "FREQ=WEEKLY;BYDAY=MO,WE" のようなRRULEの文字列を変換する
+// 先頭の "RRULE:" は省略できる
+func ParseRecurrence(raw string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+	rule := strings.TrimSpace(raw)
+	if len(rule) > MaxRecurrenceLength {
+		return Recurrence{}, fmt.Errorf("invalid recurrence: must be at most %d characters", MaxRecurrenceLength)
+	}
+	rule = strings.TrimPrefix(strings.ToUpper(rule), "RRULE:")
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return Recurrence{}, fmt.Errorf("invalid recurrence %q: expected KEY=VALUE", part)
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if seen[key] {
+			return Recurrence{}, fmt.Errorf("invalid recurrence: %s is specified more than once", key)
+		}
+		seen[key] = true
+		var err error
+		switch key {
+		case "FREQ":
+			if value != FreqDaily && value != FreqWeekly && value != FreqMonthly {
+				return Recurrence{}, fmt.Errorf("invalid recurrence: FREQ must be %s, %s or %s", FreqDaily, FreqWeekly, FreqMonthly)
+			}
+			r.Freq = value
+		case "INTERVAL":
+			r.Interval, err = parseRuleInt(key, value, 1, maxRecurrenceInterval)
+		case "COUNT":
+			r.Count, err = parseRuleInt(key, value, 1, maxRecurrenceCount)
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				weekday, ok := parseWeekday(code)
+				if !ok {
+					return Recurrence{}, fmt.Errorf("invalid recurrence: unknown BYDAY %q", code)
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				day, err := parseRuleInt(key, v, -31, 31)
+				if err != nil || day == 0 {
+					return Recurrence{}, fmt.Errorf("invalid recurrence: BYMONTHDAY must be 1 to 31 or -31 to -1")
+				}
+				r.ByMonthDay = append(r.ByMonthDay, day)
+			}
+		case "UNTIL":
+			until, err := time.Parse(untilLayout, value)
+			if err != nil {
+				until, err = time.Parse(untilDateLayout, value)
+				if err != nil {
+					return Recurrence{}, fmt.Errorf("invalid recurrence: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+				}
+				until = until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			r.Until = &until
+		default:
+			return Recurrence{}, fmt.Errorf("invalid recurrence: %s is not supported", key)
+		}
+		if err != nil {
+			return Recurrence{}, err
+		}
+	}
+	switch {
+	case r.Freq == "":
+		return Recurrence{}, fmt.Errorf("invalid recurrence: FREQ is required")
+	case r.Count > 0 && r.Until != nil:
+		return Recurrence{}, fmt.Errorf("invalid recurrence: COUNT and UNTIL cannot be used together")
+	case r.Freq == FreqWeekly && len(r.ByMonthDay) > 0:
+		return Recurrence{}, fmt.Errorf("invalid recurrence: BYMONTHDAY is not supported with FREQ=%s", FreqWeekly)
+	case r.Freq == FreqMonthly && len(r.ByDay) > 0:
+		return Recurrence{}, fmt.Errorf("invalid recurrence: BYDAY is not supported with FREQ=%s", FreqMonthly)
+	}
+	return r, nil
+}
+
+func parseRuleInt(key, value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("invalid recurrence: %s must be %d to %d", key, min, max)
+	}
+	return n, nil
+}
+
+func parseWeekday(code string) (time.Weekday, bool) {
+	for i, v := range weekdayCodes {
+		if v == strings.TrimSpace(code) {
+			return time.Weekday(i), true
+		}
+	}
+	return 0, false
+}
+
+// String はルールをRRULEの文字列に変換する
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := []string{}
+		for _, weekday := range r.ByDay {
+			codes = append(codes, weekdayCodes[weekday])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := []string{}
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Anchor は省略されたBYDAY及びBYMONTHDAYを最初の日時から補う
+// 曜日及び日は最初の日時のタイムゾーンで判定し、補ったルールは最初の日時が変わっても同じ曜日及び日に繰り返す
+func (r Recurrence) Anchor(dtstart time.Time) Recurrence {
+	if r.Freq == FreqWeekly && len(r.ByDay) == 0 {
+		r.ByDay = []time.Weekday{dtstart.Weekday()}
+	}
+	if r.Freq == FreqMonthly && len(r.ByMonthDay) == 0 {
+		r.ByMonthDay = []int{dtstart.Day()}
+	}
+	return r
+}
+
+// Occurrences は最初の日時より後の日時を最大n件、UTCで返却する
+// 日時は最初の日時のタイムゾーンでの時刻を保つため、夏時間の前後でもその地域の同じ時刻となる
+// COUNTは最初の日時を1回目として数える
+func (r Recurrence) Occurrences(dtstart time.Time, n int) []time.Time {
+	r = r.Anchor(dtstart)
+	if r.Count > 0 && r.Count-1 < n {
+		n = r.Count - 1
+	}
+	result := []time.Time{}
+	for period := 0; len(result) < n && period < maxRecurrencePeriods; period++ {
+		for _, t := range r.candidates(dtstart, period) {
+			if !t.After(dtstart) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return result
+			}
+			result = append(result, t.UTC())
+			if len(result) == n {
+				return result
+			}
+		}
+	}
+	return result
+}
+
+// Advance は次の日時を最初の日時とするルールを返却する
+// COUNTがある場合は残りの回数を減らす
+func (r Recurrence) Advance(dtstart time.Time) Recurrence {
+	r = r.Anchor(dtstart)
+	if r.Count > 0 {
+		r.Count--
+	}
+	return r
+}
+
+// candidates は最初の日時から数えてperiod番目の期間に含まれる日時を昇順で返却する
+func (r Recurrence) candidates(dtstart time.Time, period int) []time.Time {
+	switch r.Freq {
+	case FreqDaily:
+		day := dtstart.AddDate(0, 0, period*r.Interval)
+		if len(r.ByDay) > 0 && !containsWeekday(r.ByDay, day.Weekday()) {
+			return nil
+		}
+		if len(r.ByMonthDay) > 0 && !containsInt(resolveMonthDays(r.ByMonthDay, day), day.Day()) {
+			return nil
+		}
+		return []time.Time{day}
+	case FreqWeekly:
+		weekStart := dtstart.AddDate(0, 0, -mondayOffset(dtstart.Weekday())+7*period*r.Interval)
+		offsets := []int{}
+		for _, weekday := range r.ByDay {
+			offsets = append(offsets, mondayOffset(weekday))
+		}
+		sort.Ints(offsets)
+		result := []time.Time{}
+		for i, offset := range offsets {
+			if i > 0 && offsets[i-1] == offset {
+				continue
+			}
+			result = append(result, weekStart.AddDate(0, 0, offset))
+		}
+		return result
+	case FreqMonthly:
+		month := time.Date(dtstart.Year(), dtstart.Month()+time.Month(period*r.Interval), 1,
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location())
+		result := []time.Time{}
+		for _, day := range resolveMonthDays(r.ByMonthDay, month) {
+			result = append(result, month.AddDate(0, 0, day-1))
+		}
+		return result
+	}
+	return nil
+}
+
+// resolveMonthDays はBYMONTHDAYの値を指定の月の日に変換し、存在しない日を除いて昇順で返却する
+func resolveMonthDays(monthDays []int, month time.Time) []int {
+	daysInMonth := time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	days := []int{}
+	for _, day := range monthDays {
+		if day < 0 {
+			day = daysInMonth + day + 1
+		}
+		if day >= 1 && day <= daysInMonth && !containsInt(days, day) {
+			days = append(days, day)
+		}
+	}
+	sort.Ints(days)
+	return days
+}
+
+// mondayOffset は月曜日から数えた曜日の日数
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func containsWeekday(weekdays []time.Weekday, target time.Weekday) bool {
+	for _, v := range weekdays {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, target int) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Location は繰り返しのルールを計算するタイムゾーンを返却する
+// 指定がないか読み込めないタイムゾーンの場合はUTCとする
+func (t Todo) Location() *time.Location {
+	if t.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// NextOccurrence は繰り返しのTodoを完了した際に作成する次のTodoを返却する
+// 次のTodoは期限を次の日時に進め、開始日時も同じだけ進める。Statusはワークフローの最初のStatusとする
+// 繰り返しがないか、期限がないか、繰り返しが終了している場合はfalseを返却する
+func (w Workflow) NextOccurrence(todo Todo, now time.Time) (Todo, bool) {
+	if todo.Recurrence == "" || todo.DueAt == nil || len(w.States) == 0 {
+		return Todo{}, false
+	}
+	rule, err := ParseRecurrence(todo.Recurrence)
+	if err != nil {
+		return Todo{}, false
+	}
+	dtstart := todo.DueAt.In(todo.Location())
+	next := rule.Occurrences(dtstart, 1)
+	if len(next) == 0 {
+		return Todo{}, false
+	}
+	dueAt := next[0]
+	var startAt *time.Time
+	if todo.StartAt != nil {
+		shifted := todo.StartAt.Add(dueAt.Sub(*todo.DueAt))
+		startAt = &shifted
+	}
+	occurrence := Todo{
+		UserID:     todo.UserID,
+		ProjectID:  todo.ProjectID,
+		ParentID:   todo.ParentID,
+		Title:      todo.Title,
+		Details:    todo.Details,
+		Priority:   todo.Priority,
+		StartAt:    startAt,
+		DueAt:      &dueAt,
+		Recurrence: rule.Advance(dtstart).String(),
+		Timezone:   todo.Timezone,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Tags:       todo.Tags,
+	}
+	if err := w.Apply(&occurrence, w.States[0], now); err != nil {
+		return Todo{}, false
+	}
+	return occurrence, true
+}
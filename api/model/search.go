@@ -0,0 +1,150 @@
+package model
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// HighlightStart は検索結果の抜粋で一致箇所の前に挿入する文字列
+	HighlightStart = "<mark>"
+	// HighlightStop は検索結果の抜粋で一致箇所の後に挿入する文字列
+	HighlightStop = "</mark>"
+	// HighlightStartSentinel はDBで生成した抜粋で一致箇所の前を示す区切り文字
+	HighlightStartSentinel = "\x02"
+	// HighlightStopSentinel はDBで生成した抜粋で一致箇所の後を示す区切り文字
+	HighlightStopSentinel = "\x03"
+	// snippetLength は抜粋の文字数の目安
+	snippetLength = 120
+)
+
+// TodoSearchResult はTodoの全文検索の結果
+type TodoSearchResult struct {
+	Todo
+	Rank           float64
+	TitleSnippet   string
+	DetailsSnippet string
+}
+
+// SearchTerms は検索文字列を空白で区切った検索語の一覧を返却する
+func SearchTerms(query string) []string {
+	return strings.Fields(query)
+}
+
+// TodoSearcher は全文検索を利用できないDB向けに、検索語による一致判定と抜粋の生成を行う
+type TodoSearcher struct {
+	terms   []string
+	pattern *regexp.Regexp
+}
+
+// NewTodoSearcher は検索文字列から TodoSearcher を生成する
+func NewTodoSearcher(query string) TodoSearcher {
+	terms := SearchTerms(query)
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	return TodoSearcher{
+		terms:   terms,
+		pattern: regexp.MustCompile("(?i)" + strings.Join(quoted, "|")),
+	}
+}
+
+// Search はTodoが全ての検索語をTitleかDetailsに含む場合に検索結果を返却する
+// Titleでの一致はDetailsでの一致より高く評価する
+func (s TodoSearcher) Search(todo Todo) (TodoSearchResult, bool) {
+	if len(s.terms) == 0 {
+		return TodoSearchResult{}, false
+	}
+	title, details := strings.ToLower(todo.Title), strings.ToLower(todo.Details)
+	rank := 0.0
+	for _, term := range s.terms {
+		term = strings.ToLower(term)
+		inTitle, inDetails := strings.Count(title, term), strings.Count(details, term)
+		if inTitle+inDetails == 0 {
+			return TodoSearchResult{}, false
+		}
+		rank += float64(2*inTitle + inDetails)
+	}
+	return TodoSearchResult{
+		Todo:           todo,
+		Rank:           rank,
+		TitleSnippet:   s.Highlight(todo.Title),
+		DetailsSnippet: s.Highlight(snippet(todo.Details, s.pattern.FindStringIndex(todo.Details))),
+	}, true
+}
+
+// Highlight はHTMLエスケープした文字列の、検索語に一致する箇所を HighlightStart と HighlightStop で囲む
+func (s TodoSearcher) Highlight(text string) string {
+	if len(s.terms) == 0 {
+		return html.EscapeString(text)
+	}
+	var b strings.Builder
+	last := 0
+	for _, match := range s.pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:match[0]]))
+		b.WriteString(HighlightStart + html.EscapeString(text[match[0]:match[1]]) + HighlightStop)
+		last = match[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// highlightReplacer はHTMLエスケープした抜粋の区切り文字を置き換える
+var highlightReplacer = strings.NewReplacer(HighlightStartSentinel, HighlightStart, HighlightStopSentinel, HighlightStop)
+
+// EscapeHighlight は区切り文字で一致箇所を示した抜粋をHTMLエスケープし、区切り文字を HighlightStart と HighlightStop に置き換える
+func EscapeHighlight(text string) string {
+	return highlightReplacer.Replace(html.EscapeString(text))
+}
+
+// snippet は長い文字列から一致箇所の周辺のみを切り出す
+func snippet(text string, match []int) string {
+	if utf8.RuneCountInString(text) <= snippetLength {
+		return text
+	}
+	runes := []rune(text)
+	start := 0
+	if match != nil {
+		start = utf8.RuneCountInString(text[:match[0]]) - snippetLength/3
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetLength
+	if end > len(runes) {
+		end = len(runes)
+	}
+	result := string(runes[start:end])
+	if start > 0 {
+		result = "..." + result
+	}
+	if end < len(runes) {
+		result = result + "..."
+	}
+	return result
+}
+
+// RankTodoSearch はTodoリストから検索語に一致するItemを関連度の高い順に最大limit件返却する
+func RankTodoSearch(todoList TodoList, query string, limit int) []TodoSearchResult {
+	searcher := NewTodoSearcher(query)
+	results := []TodoSearchResult{}
+	for _, todo := range todoList {
+		if result, ok := searcher.Search(todo); ok {
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].ID < results[j].ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// HasAncestor はTodoの親を辿ってancestorに到達するかを判定する
+// parentsはTodoのID毎の親のIDで、親を持たないTodoは含まない
+func HasAncestor(parents map[uint]uint, id uint, ancestor uint) bool {
+	seen := map[uint]bool{}
+	for !seen[id] {
+		if id == ancestor {
+			return true
+		}
+		seen[id] = true
+		parent, ok := parents[id]
+		if !ok {
+			return false
+		}
+		id = parent
+	}
+	return false
+}
+
+// Progress は子のTodoのStatusから完了率 (%) を計算する
+// 終了したStatusの子は母数に含めず、全ての子が終了している場合は100とする。子を持たない場合はnilを返却する
+func (w Workflow) Progress(childStatuses []string) *int {
+	if len(childStatuses) == 0 {
+		return nil
+	}
+	total, completed := 0, 0
+	for _, status := range childStatuses {
+		if containsString(w.ClosedStates, status) {
+			continue
+		}
+		total++
+		if w.IsCompleted(status) {
+			completed++
+		}
+	}
+	progress := 100
+	if total > 0 {
+		progress = completed * 100 / total
+	}
+	return &progress
+}
+
+// StatusChange はワークフローに従ってStatusを変更したTodoと変更前のStatus
+type StatusChange struct {
+	Todo Todo
+	From string
+}
+
+// CompleteDescendants はTodoを完了する際に、未完了の子孫のTodoを処理する
+// completeがfalseの場合、未完了の子孫があれば OpenChildrenError を返却する
+// completeがtrueの場合、未完了の子孫を同じStatusに変更して返却し、変更できない子孫があれば OpenChildrenError を返却する
+func (w Workflow) CompleteDescendants(descendants []Todo, to string, complete bool, now time.Time) ([]StatusChange, error) {
+	changes := []StatusChange{}
+	blocked := []uint{}
+	for _, todo := range descendants {
+		if w.IsFinished(todo.Status) {
+			continue
+		}
+		from := todo.Status
+		if !complete {
+			blocked = append(blocked, todo.ID)
+			continue
+		}
+		todo.UpdatedAt = now
+		if err := w.Apply(&todo, to, now); err != nil {
+			blocked = append(blocked, todo.ID)
+			continue
+		}
+		changes = append(changes, StatusChange{Todo: todo, From: from})
+	}
+	if len(blocked) > 0 {
+		return nil, &OpenChildrenError{IDs: blocked}
+	}
+	return changes, nil
+}
+
+// OpenChildrenError は完了できない未完了の子孫を持つTodoを完了しようとした場合のエラー
+type OpenChildrenError struct {
+	IDs []uint
+}
+
+func (e *OpenChildrenError) Error() string {
+	return fmt.Sprintf("todo has %d open subtasks", len(e.IDs))
+}
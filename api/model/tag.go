@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTagNameLength はタグ名の最大文字数
+	MaxTagNameLength = 50
+	// MaxTagsPerTodo は1つのTodoに設定できるタグの最大数
+	MaxTagsPerTodo = 20
+)
+
+type Tag struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint
+	Name      string
+	CreatedAt time.Time
+}
+
+// TodoTag はTodoとタグの関連
+type TodoTag struct {
+	TodoID uint `gorm:"primaryKey"`
+	TagID  uint `gorm:"primaryKey"`
+}
+
+// TagCount はタグと、そのタグが設定されたTodoの件数
+type TagCount struct {
+	Tag
+	Count int
+}
+
+// NormalizeTagName はタグ名の前後の空白を除去し、利用できる名前かを検証する
+// カンマはクエリパラメータの区切り文字のため利用できない
+func NormalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxTagNameLength || strings.Contains(name, ",") {
+		return "", fmt.Errorf("invalid tag name %q: must be 1 to %d characters without commas", name, MaxTagNameLength)
+	}
+	return name, nil
+}
+
+// NormalizeTagNames はTodoに設定するタグ名の一覧を検証し、重複を除いて返却する
+func NormalizeTagNames(names []string) ([]string, error) {
+	result := []string{}
+	for _, name := range names {
+		normalized, err := NormalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		if !containsString(result, normalized) {
+			result = append(result, normalized)
+		}
+	}
+	if len(result) > MaxTagsPerTodo {
+		return nil, fmt.Errorf("too many tags: up to %d tags can be set", MaxTagsPerTodo)
+	}
+	return result, nil
+}
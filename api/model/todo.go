@@ -1,15 +1,43 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Todo struct {
-	ID        uint `gorm:"primaryKey"`
-	Title     string
-	Status    string
-	Details   string
-	Priority  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          uint `gorm:"primaryKey"`
+	UserID      uint
+	ProjectID   *uint
+	ParentID    *uint
+	Title       string
+	Status      string
+	Details     string
+	Priority    Priority
+	StartAt     *time.Time
+	DueAt       *time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	// Recurrence は繰り返しのルール (RRULE) で、繰り返さない場合は空文字
+	Recurrence string
+	// Timezone は繰り返しのルールを計算するタイムゾーン (例: Asia/Tokyo) で、空文字はUTCを表す
+	Timezone string
+	// ArchivedAt はアーカイブした日時で、アーカイブしたItemは既定の一覧に含まない
+	ArchivedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	// DeletedAt はゴミ箱に移動した日時で、ゴミ箱のItemは通常の取得及び更新の対象にならない
+	DeletedAt gorm.DeletedAt
+	// Tags はタグ名の一覧で、todo_tags テーブルから読み込む
+	Tags []string `gorm:"-"`
+	// Progress は子のTodoの完了率 (%) で、子を持たない場合はnil
+	Progress *int `gorm:"-"`
+	// BlockedBy はこのTodoが終了を待つTodoのID、Blocking はこのTodoの終了を待つTodoのID
+	BlockedBy []uint `gorm:"-"`
+	Blocking  []uint `gorm:"-"`
 }
 
 type NewTodo struct {
@@ -27,9 +55,291 @@ type Payload struct {
 	Title    string
 	Status   string
 	Details  string
-	Priority string
+	Priority Priority
+	StartAt  *time.Time
+	DueAt    *time.Time
+	// Tags がnilの場合、更新時はタグを変更しない
+	Tags []string
+	// ProjectID がnilの場合、更新時はプロジェクトを変更しない。0はプロジェクトに属さないことを表す
+	ProjectID *uint
+	// ParentID がnilの場合、更新時は親を変更しない。0は親を持たないことを表す
+	ParentID *uint
+	// Recurrence がnilの場合、更新時は繰り返しを変更しない。空文字は繰り返さないことを表す
+	Recurrence *string
+	// Timezone は繰り返しのルールを計算するタイムゾーンで、Recurrence と共に保存する
+	Timezone string
+	// Archived がnilの場合、更新時はアーカイブの状態を変更しない
+	Archived *bool
+	// CompleteChildren がtrueの場合、完了に変更する際に未完了の子孫も同じStatusに変更する
+	CompleteChildren bool
 }
 
 type Status struct {
 	Status string
+	// CompleteChildren がtrueの場合、完了に変更する際に未完了の子孫も同じStatusに変更する
+	CompleteChildren bool
+}
+
+// TodoFilter はTodoリストの絞り込み条件
+// 空の項目は絞り込みに利用しない
+type TodoFilter struct {
+	Statuses      []string
+	Priorities    []Priority
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	// DueAfter 及び DueBefore を指定した場合、期限のないItemは含まない
+	DueAfter        *time.Time
+	DueBefore       *time.Time
+	Title           string
+	ExcludeStatuses []string
+	// Tags のいずれか (TagsMatchAll がtrueの場合は全て) が設定されたItemに絞り込む
+	Tags         []string
+	TagsMatchAll bool
+	// ProjectID を指定した場合、そのプロジェクトのItemに絞り込む。0はプロジェクトに属さないItemを表す
+	ProjectID *uint
+	// ParentID を指定した場合、そのTodoの子のItemに絞り込む。0は親を持たないItemを表す
+	ParentID *uint
+	// Archived がtrueの場合はアーカイブしたItemのみ、falseの場合はアーカイブしていないItemのみとする
+	Archived bool
+}
+
+// Match はTodoが絞り込み条件に一致するかを判定する
+// 日時の範囲は After を含み Before を含まない
+func (f TodoFilter) Match(todo Todo) bool {
+	if len(f.Statuses) > 0 && !containsString(f.Statuses, todo.Status) {
+		return false
+	}
+	if len(f.Priorities) > 0 && !containsPriority(f.Priorities, todo.Priority) {
+		return false
+	}
+	if !inRange(todo.CreatedAt, f.CreatedAfter, f.CreatedBefore) {
+		return false
+	}
+	if !inRange(todo.UpdatedAt, f.UpdatedAfter, f.UpdatedBefore) {
+		return false
+	}
+	if (f.DueAfter != nil || f.DueBefore != nil) && (todo.DueAt == nil || !inRange(*todo.DueAt, f.DueAfter, f.DueBefore)) {
+		return false
+	}
+	if containsString(f.ExcludeStatuses, todo.Status) {
+		return false
+	}
+	if f.ProjectID != nil && optionalID(todo.ProjectID) != *f.ProjectID {
+		return false
+	}
+	if f.ParentID != nil && optionalID(todo.ParentID) != *f.ParentID {
+		return false
+	}
+	if len(f.Tags) > 0 && !f.matchTags(todo.Tags) {
+		return false
+	}
+	if f.Title != "" && !strings.Contains(strings.ToLower(todo.Title), strings.ToLower(f.Title)) {
+		return false
+	}
+	if (todo.ArchivedAt != nil) != f.Archived {
+		return false
+	}
+	return true
+}
+
+func (f TodoFilter) matchTags(tags []string) bool {
+	for _, tag := range f.Tags {
+		found := containsString(tags, tag)
+		if found && !f.TagsMatchAll {
+			return true
+		}
+		if !found && f.TagsMatchAll {
+			return false
+		}
+	}
+	return f.TagsMatchAll
+}
+
+// optionalID は省略可能なIDを、省略時を0として返却する
+func optionalID(id *uint) uint {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPriority(values []Priority, target Priority) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func inRange(t time.Time, after, before *time.Time) bool {
+	if after != nil && t.Before(*after) {
+		return false
+	}
+	if before != nil && !t.Before(*before) {
+		return false
+	}
+	return true
+}
+
+// SortableFields はTodoリストの並び替えに利用できる項目
+// 値のないItemを含む due_at はカーソルで続きを表せないため指定できない
+var SortableFields = []string{"id", "title", "status", "priority", "created_at", "updated_at"}
+
+// SortKey はTodoリストの並び替えの項目と向き
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// TodoSort はTodoリストの並び替え条件
+// 順序を一意に定めるため、末尾は必ず id となる
+type TodoSort []SortKey
+
+// ParseTodoSort は "-priority,created_at" 形式の文字列から並び替え条件を生成する
+// 先頭の "-" は降順を表す
+func ParseTodoSort(raw string) (TodoSort, error) {
+	keys := TodoSort{}
+	seen := map[string]bool{}
+	if raw != "" {
+		for _, v := range strings.Split(raw, ",") {
+			key := SortKey{Field: strings.TrimSpace(v)}
+			if strings.HasPrefix(key.Field, "-") {
+				key.Field, key.Desc = key.Field[1:], true
+			}
+			if !containsString(SortableFields, key.Field) || seen[key.Field] {
+				return nil, fmt.Errorf("invalid sort field: %q", v)
+			}
+			seen[key.Field] = true
+			keys = append(keys, key)
+		}
+	}
+	if !seen["id"] {
+		keys = append(keys, SortKey{Field: "id"})
+	}
+	return keys, nil
+}
+
+// String は並び替え条件を ParseTodoSort で解釈できる形式で返却する
+func (s TodoSort) String() string {
+	fields := make([]string, 0, len(s))
+	for _, key := range s {
+		if key.Desc {
+			fields = append(fields, "-"+key.Field)
+		} else {
+			fields = append(fields, key.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// Compare は並び替え条件に従って a と b を比較する
+// a が先なら負、b が先なら正、同順なら 0 を返却する
+func (s TodoSort) Compare(a, b Todo) int {
+	for _, key := range s {
+		result := compareField(a, b, key.Field)
+		if key.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// FieldValue は並び替え項目に対応するTodoの値を返却する
+func (t Todo) FieldValue(field string) interface{} {
+	switch field {
+	case "title":
+		return t.Title
+	case "status":
+		return t.Status
+	case "priority":
+		return t.Priority.Rank()
+	case "created_at":
+		return t.CreatedAt
+	case "updated_at":
+		return t.UpdatedAt
+	default:
+		return t.ID
+	}
+}
+
+func compareField(a, b Todo, field string) int {
+	switch x := a.FieldValue(field).(type) {
+	case string:
+		return strings.Compare(x, b.FieldValue(field).(string))
+	case int:
+		y := b.FieldValue(field).(int)
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+		return 0
+	case time.Time:
+		return compareTime(x, b.FieldValue(field).(time.Time))
+	default:
+		y := b.FieldValue(field).(uint)
+		if x.(uint) < y {
+			return -1
+		}
+		if x.(uint) > y {
+			return 1
+		}
+		return 0
+	}
+}
+
+func compareTime(x, y time.Time) int {
+	if x.Before(y) {
+		return -1
+	}
+	if x.After(y) {
+		return 1
+	}
+	return 0
+}
+
+// TodoPage はTodoリストのページ指定
+// After が指定された場合、並び替え順で After より後のItemのみを対象とする
+type TodoPage struct {
+	Sort  TodoSort
+	Limit int
+	After *Todo
+}
+
+// SchedulePage は期限の近い順のTodoリストのページ指定
+// After が指定された場合、期限及びIDの順で After より後のItemのみを対象とする
+type SchedulePage struct {
+	Limit int
+	After *Todo
+}
+
+// CompareDue は期限及びIDの順に a と b を比較する
+// 期限のないItemは比較できないため、呼び出し側で除外すること
+func CompareDue(a, b Todo) int {
+	if result := compareTime(*a.DueAt, *b.DueAt); result != 0 {
+		return result
+	}
+	if a.ID < b.ID {
+		return -1
+	}
+	if a.ID > b.ID {
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type RevokedToken struct {
+	JTI       string `gorm:"primaryKey;column:jti"`
+	UserID    uint
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
@@ -3,11 +3,18 @@ package model
 import "time"
 
 type User struct {
-	ID        uint `gorm:"primaryKey"`
-	Name      string
-	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       uint `gorm:"primaryKey"`
+	Name     string
+	Password string
+	// PasswordChangedAt はパスワードを最後に変更した日時で、変更前に発行したリフレッシュトークンは利用できない
+	PasswordChangedAt *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
 }
 
 type ID uint
+
+type UserPayload struct {
+	Name     string
+	Password string
+}
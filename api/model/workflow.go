@@ -0,0 +1,191 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 既定のワークフローのStatus
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusBlocked    = "blocked"
+	StatusDone       = "done"
+	StatusCancelled  = "cancelled"
+)
+
+// MaxStatusLength はStatusカラムに保存できる最大文字数
+const MaxStatusLength = 20
+
+// Workflow はTodoのStatusの一覧と許可される遷移の定義
+type Workflow struct {
+	States      []string            `json:"states"`
+	Transitions map[string][]string `json:"transitions"`
+	// StartedStates に遷移した際に started_at を記録する
+	StartedStates []string `json:"started_states"`
+	// CompletedStates に遷移した際に completed_at を記録し、他のStatusに戻した際に消去する
+	CompletedStates []string `json:"completed_states"`
+	// ClosedStates は完了せずに終了したことを表すStatus
+	ClosedStates []string `json:"closed_states"`
+}
+
+// DefaultWorkflow はユーザーがワークフローを設定していない場合に利用するワークフロー
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		States: []string{StatusTodo, StatusInProgress, StatusBlocked, StatusDone, StatusCancelled},
+		Transitions: map[string][]string{
+			StatusTodo:       {StatusInProgress, StatusBlocked, StatusDone, StatusCancelled},
+			StatusInProgress: {StatusTodo, StatusBlocked, StatusDone, StatusCancelled},
+			StatusBlocked:    {StatusTodo, StatusInProgress, StatusCancelled},
+			StatusDone:       {StatusTodo},
+			StatusCancelled:  {StatusTodo},
+		},
+		StartedStates:   []string{StatusInProgress},
+		CompletedStates: []string{StatusDone},
+		ClosedStates:    []string{StatusCancelled},
+	}
+}
+
+// UserWorkflow はユーザー毎に設定されたワークフロー
+type UserWorkflow struct {
+	UserID     uint     `gorm:"primaryKey"`
+	Definition Workflow `gorm:"type:text"`
+	UpdatedAt  time.Time
+}
+
+// Value はワークフローをJSONとしてDBに保存する
+func (w Workflow) Value() (driver.Value, error) {
+	raw, err := json.Marshal(w)
+	return string(raw), err
+}
+
+// Scan はDBに保存されたJSONからワークフローを復元する
+func (w *Workflow) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, w)
+	case string:
+		return json.Unmarshal([]byte(v), w)
+	default:
+		return fmt.Errorf("unsupported workflow value: %T", value)
+	}
+}
+
+// Validate はワークフローの定義が正しいかを検証する
+func (w Workflow) Validate() error {
+	errs := []string{}
+	if len(w.States) == 0 {
+		errs = append(errs, "states must not be empty")
+	}
+	seen := map[string]bool{}
+	for _, state := range w.States {
+		if state == "" || len(state) > MaxStatusLength {
+			errs = append(errs, fmt.Sprintf("state %q must be 1 to %d characters", state, MaxStatusLength))
+		}
+		if seen[state] {
+			errs = append(errs, fmt.Sprintf("state %q is duplicated", state))
+		}
+		seen[state] = true
+	}
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			errs = append(errs, fmt.Sprintf("transition from unknown state %q", from))
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				errs = append(errs, fmt.Sprintf("transition to unknown state %q", to))
+			}
+		}
+	}
+	for _, state := range append(append(append([]string{}, w.StartedStates...), w.CompletedStates...), w.ClosedStates...) {
+		if !seen[state] {
+			errs = append(errs, fmt.Sprintf("unknown state %q", state))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// HasState はStatusがワークフローに定義されているかを判定する
+func (w Workflow) HasState(state string) bool {
+	return containsString(w.States, state)
+}
+
+// NextStates は指定のStatusから遷移できるStatusの一覧を返却する
+// ワークフローに定義されていないStatusからは全てのStatusに遷移できる
+func (w Workflow) NextStates(from string) []string {
+	if !w.HasState(from) {
+		return w.States
+	}
+	next := w.Transitions[from]
+	if next == nil {
+		return []string{}
+	}
+	return next
+}
+
+// IsCompleted はStatusが完了を表すかを判定する
+func (w Workflow) IsCompleted(state string) bool {
+	return containsString(w.CompletedStates, state)
+}
+
+// FinishedStates は完了または終了したことを表すStatusの一覧を返却する
+func (w Workflow) FinishedStates() []string {
+	return append(append([]string{}, w.CompletedStates...), w.ClosedStates...)
+}
+
+// IsFinished はStatusが完了または終了したことを表すかを判定する
+func (w Workflow) IsFinished(state string) bool {
+	return containsString(w.FinishedStates(), state)
+}
+
+// Apply はワークフローに従ってTodoのStatusを変更し、遷移に応じた日時を記録する
+// Statusが新規作成時のように空の場合は、定義された任意のStatusを設定できる
+func (w Workflow) Apply(todo *Todo, to string, now time.Time) error {
+	if !w.HasState(to) {
+		return &StatusError{Status: to, Valid: w.States}
+	}
+	if todo.Status == to {
+		return nil
+	}
+	if todo.Status != "" && !containsString(w.NextStates(todo.Status), to) {
+		return &TransitionError{From: todo.Status, To: to, Allowed: w.NextStates(todo.Status)}
+	}
+	todo.Status = to
+	if containsString(w.StartedStates, to) && todo.StartedAt == nil {
+		todo.StartedAt = &now
+	}
+	if w.IsCompleted(to) {
+		todo.CompletedAt = &now
+	} else {
+		todo.CompletedAt = nil
+	}
+	return nil
+}
+
+// StatusError はワークフローに定義されていないStatusが指定された場合のエラー
+type StatusError struct {
+	Status string
+	Valid  []string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unknown status %q: valid statuses are %s", e.Status, strings.Join(e.Valid, ", "))
+}
+
+// TransitionError はワークフローで許可されていない遷移が指定された場合のエラー
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change status from %q to %q", e.From, e.To)
+}
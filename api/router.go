@@ -6,6 +6,8 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/Z-me/practice-todo-api/api/handler"
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/repository"
 	"github.com/Z-me/practice-todo-api/middleware"
 )
 
@@ -15,17 +17,66 @@ func Test() {
 }
 
 // Router main router
-func Router() *gin.Engine {
+func Router(cfg config.Config, repos repository.Repositories) *gin.Engine {
+	if !cfg.Log.IsDebug() {
+		gin.SetMode(gin.ReleaseMode)
+	}
 	router := gin.Default()
+	h := handler.New(cfg, repos)
 
-	router.Use(middleware.LoginCheckMiddleware())
+	// Note: 認証不要のルート
+	router.POST("/users", h.CreateUser)
+	router.POST("/auth/token", h.IssueToken)
+	router.POST("/auth/refresh", h.RefreshToken)
+	router.POST("/auth/logout", h.Logout)
 
-	router.GET("/todo", handler.GetTodoList)
-	router.GET("/todo/:id", handler.GetTodoItemByID)
-	router.POST("/todo", handler.AddNewTodo)
-	router.PUT("/todo/:id", handler.UpdateTodoItem)
-	router.PATCH("/todo/:id/status", handler.UpdateTodoState)
-	router.DELETE("/todo/:id", handler.DeleteTodoListItem)
+	authorized := router.Group("/")
+	authorized.Use(middleware.LoginCheckMiddleware(cfg, repos))
+
+	// Note: アカウント及びAPIキーの管理はAPIキーでは行えない
+	account := authorized.Group("/")
+	account.Use(middleware.RequireUserCredential())
+	account.GET("/me", h.GetMe)
+	account.PATCH("/me", h.UpdateMe)
+	account.DELETE("/me", h.DeleteMe)
+	account.GET("/api-keys", h.GetAPIKeyList)
+	account.POST("/api-keys", h.CreateAPIKey)
+	account.DELETE("/api-keys/:id", h.DeleteAPIKey)
+	account.GET("/me/workflow", h.GetWorkflow)
+	account.PUT("/me/workflow", h.UpdateWorkflow)
+	account.DELETE("/me/workflow", h.ResetWorkflow)
+
+	// Note: 更新系のルートは読み取り専用のAPIキーでは利用できない
+	writable := middleware.RequireWriteScope()
+	authorized.GET("/todo", h.GetTodoList)
+	authorized.GET("/todo/search", h.SearchTodo)
+	authorized.GET("/todo/overdue", h.GetOverdueTodoList)
+	authorized.GET("/todo/upcoming", h.GetUpcomingTodoList)
+	authorized.GET("/todo/:id", h.GetTodoItemByID)
+	authorized.GET("/todo/:id/children", h.GetTodoChildren)
+	authorized.GET("/todo/:id/occurrences", h.GetTodoOccurrences)
+	authorized.POST("/todo", writable, h.AddNewTodo)
+	authorized.POST("/todo/archive-completed", writable, h.ArchiveCompletedTodo)
+	authorized.POST("/todo/bulk", writable, h.BulkTodo)
+	authorized.PUT("/todo/:id", writable, h.UpdateTodoItem)
+	authorized.PATCH("/todo/:id/status", writable, h.UpdateTodoState)
+	authorized.DELETE("/todo/:id", writable, h.DeleteTodoListItem)
+	authorized.POST("/todo/:id/dependencies", writable, h.AddDependency)
+	authorized.DELETE("/todo/:id/dependencies/:blocker_id", writable, h.RemoveDependency)
+	authorized.DELETE("/todo/:id/recurrence", writable, h.StopTodoRecurrence)
+	authorized.POST("/todo/:id/restore", writable, h.RestoreTodoItem)
+	authorized.GET("/trash", h.GetTrashList)
+	authorized.DELETE("/trash/:id", writable, h.PurgeTrashItem)
+	authorized.GET("/tags", h.GetTagList)
+	authorized.POST("/tags", writable, h.CreateTag)
+	authorized.PATCH("/tags/:id", writable, h.RenameTag)
+	authorized.DELETE("/tags/:id", writable, h.DeleteTag)
+	authorized.GET("/projects", h.GetProjectList)
+	authorized.GET("/projects/:id", h.GetProjectByID)
+	authorized.GET("/projects/:id/todos", h.GetProjectTodoList)
+	authorized.POST("/projects", writable, h.CreateProject)
+	authorized.PATCH("/projects/:id", writable, h.UpdateProject)
+	authorized.DELETE("/projects/:id", writable, h.DeleteProject)
 
 	return router
 }
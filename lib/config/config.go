@@ -0,0 +1,303 @@
+package config
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config はサーバー全体の設定
+type Config struct {
+	Server ServerConfig `yaml:"server"`
+	DB     DBConfig     `yaml:"db"`
+	Log    LogConfig    `yaml:"log"`
+	Auth   AuthConfig   `yaml:"auth"`
+	Trash  TrashConfig  `yaml:"trash"`
+}
+
+// ServerConfig はHTTPサーバーの設定
+type ServerConfig struct {
+	Addr            string        `yaml:"addr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// DBConfig はデータベース接続及びコネクションプールの設定
+type DBConfig struct {
+	Driver          string        `yaml:"driver"`
+	DSN             string        `yaml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+}
+
+// LogConfig はログ出力の設定
+type LogConfig struct {
+	Level string `yaml:"level"`
+}
+
+// IsDebug はデバッグ用のログを出力するかを返却する
+func (l LogConfig) IsDebug() bool {
+	return l.Level == LogLevelDebug
+}
+
+// AuthConfig はトークン認証の設定
+type AuthConfig struct {
+	Secret          string        `yaml:"secret"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	// PurgeInterval は有効期限が切れた失効済みのリフレッシュトークンを削除する間隔
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+// TrashConfig はゴミ箱の設定
+type TrashConfig struct {
+	// Retention はゴミ箱に移動したTodoを完全に削除するまでの期間
+	Retention time.Duration `yaml:"retention"`
+	// PurgeInterval は保持期間を過ぎたTodoを削除する間隔
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+// ログレベル
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// DBドライバー
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	// DriverSQLite はDSNにファイルのパスまたは ":memory:" を指定する
+	DriverSQLite = "sqlite"
+	// DriverMemory はDBを利用せずプロセス内のメモリにデータを保持する
+	DriverMemory = "memory"
+)
+
+// minSecretLength はトークン署名鍵の最小の長さ
+const minSecretLength = 32
+
+// Default はデフォルトの設定を返却する
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:            "localhost:8080",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		DB: DBConfig{
+			Driver:          DriverPostgres,
+			DSN:             "host=localhost port=5432 dbname=todo_app sslmode=disable",
+			MaxOpenConns:    20,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Log: LogConfig{
+			Level: LogLevelInfo,
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+			PurgeInterval:   time.Hour,
+		},
+		Trash: TrashConfig{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+	}
+}
+
+// Load はデフォルト値、設定ファイル、環境変数の順に設定を読み込んで検証する
+// path が空の場合は環境変数 TODO_CONFIG_FILE のファイルを読み込み、それも空の場合は設定ファイルを利用しない
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path == "" {
+		path = os.Getenv("TODO_CONFIG_FILE")
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return Config{}, err
+	}
+	if cfg.Auth.Secret == "" {
+		secret, err := randomSecret()
+		if err != nil {
+			return Config{}, err
+		}
+		log.Println("auth secret is not set: issued tokens will be invalid after restart")
+		cfg.Auth.Secret = secret
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// loadFile はYAMLの設定ファイルを読み込む
+func (cfg *Config) loadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv は環境変数で設定を上書きする
+func (cfg *Config) loadEnv() error {
+	texts := []struct {
+		key    string
+		target *string
+	}{
+		{"TODO_ADDR", &cfg.Server.Addr},
+		{"TODO_DB_DRIVER", &cfg.DB.Driver},
+		{"TODO_DB_DSN", &cfg.DB.DSN},
+		{"TODO_LOG_LEVEL", &cfg.Log.Level},
+		{"TODO_AUTH_SECRET", &cfg.Auth.Secret},
+	}
+	for _, v := range texts {
+		if value, ok := os.LookupEnv(v.key); ok {
+			*v.target = value
+		}
+	}
+
+	ints := []struct {
+		key    string
+		target *int
+	}{
+		{"TODO_DB_MAX_OPEN_CONNS", &cfg.DB.MaxOpenConns},
+		{"TODO_DB_MAX_IDLE_CONNS", &cfg.DB.MaxIdleConns},
+	}
+	for _, v := range ints {
+		value, ok := os.LookupEnv(v.key)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", v.key, err)
+		}
+		*v.target = n
+	}
+
+	durations := []struct {
+		key    string
+		target *time.Duration
+	}{
+		{"TODO_READ_TIMEOUT", &cfg.Server.ReadTimeout},
+		{"TODO_WRITE_TIMEOUT", &cfg.Server.WriteTimeout},
+		{"TODO_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout},
+		{"TODO_DB_CONN_MAX_LIFETIME", &cfg.DB.ConnMaxLifetime},
+		{"TODO_DB_CONN_MAX_IDLE_TIME", &cfg.DB.ConnMaxIdleTime},
+		{"TODO_ACCESS_TOKEN_TTL", &cfg.Auth.AccessTokenTTL},
+		{"TODO_REFRESH_TOKEN_TTL", &cfg.Auth.RefreshTokenTTL},
+		{"TODO_TOKEN_PURGE_INTERVAL", &cfg.Auth.PurgeInterval},
+		{"TODO_TRASH_RETENTION", &cfg.Trash.Retention},
+		{"TODO_TRASH_PURGE_INTERVAL", &cfg.Trash.PurgeInterval},
+	}
+	for _, v := range durations {
+		value, ok := os.LookupEnv(v.key)
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", v.key, err)
+		}
+		*v.target = d
+	}
+	return nil
+}
+
+// Validate は設定値を検証し、不正な項目をまとめてエラーとして返却する
+func (cfg Config) Validate() error {
+	var errs []string
+	if cfg.Server.Addr == "" {
+		errs = append(errs, "server.addr is required")
+	}
+	if cfg.Server.ReadTimeout <= 0 {
+		errs = append(errs, "server.read_timeout must be positive")
+	}
+	if cfg.Server.WriteTimeout <= 0 {
+		errs = append(errs, "server.write_timeout must be positive")
+	}
+	if cfg.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, "server.shutdown_timeout must be positive")
+	}
+	switch cfg.DB.Driver {
+	case DriverPostgres, DriverMySQL, DriverSQLite:
+		if cfg.DB.DSN == "" {
+			errs = append(errs, "db.dsn is required")
+		}
+	case DriverMemory:
+	default:
+		errs = append(errs, "db.driver must be one of postgres, mysql, sqlite, memory")
+	}
+	if cfg.DB.MaxOpenConns <= 0 {
+		errs = append(errs, "db.max_open_conns must be positive")
+	}
+	if cfg.DB.MaxIdleConns < 0 || cfg.DB.MaxIdleConns > cfg.DB.MaxOpenConns {
+		errs = append(errs, "db.max_idle_conns must be between 0 and db.max_open_conns")
+	}
+	if cfg.DB.ConnMaxLifetime < 0 {
+		errs = append(errs, "db.conn_max_lifetime must not be negative")
+	}
+	if cfg.DB.ConnMaxIdleTime < 0 {
+		errs = append(errs, "db.conn_max_idle_time must not be negative")
+	}
+	switch cfg.Log.Level {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		errs = append(errs, "log.level must be one of debug, info, warn, error")
+	}
+	if len(cfg.Auth.Secret) < minSecretLength {
+		errs = append(errs, fmt.Sprintf("auth.secret must be at least %d bytes", minSecretLength))
+	}
+	if cfg.Auth.AccessTokenTTL <= 0 {
+		errs = append(errs, "auth.access_token_ttl must be positive")
+	}
+	if cfg.Auth.RefreshTokenTTL <= cfg.Auth.AccessTokenTTL {
+		errs = append(errs, "auth.refresh_token_ttl must be longer than auth.access_token_ttl")
+	}
+	if cfg.Auth.PurgeInterval <= 0 {
+		errs = append(errs, "auth.purge_interval must be positive")
+	}
+	if cfg.Trash.Retention <= 0 {
+		errs = append(errs, "trash.retention must be positive")
+	}
+	if cfg.Trash.PurgeInterval <= 0 {
+		errs = append(errs, "trash.purge_interval must be positive")
+	}
+	if len(errs) > 0 {
+		return errors.New("config: " + strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// randomSecret はトークン署名用のランダムな鍵を生成する
+func randomSecret() (string, error) {
+	secret := make([]byte, minSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
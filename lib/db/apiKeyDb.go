@@ -0,0 +1,73 @@
+package db
+
+import (
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/lib/util"
+	_ "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// GetAPIKeyList は指定ユーザーのAPIキーの一覧を取得
+func GetAPIKeyList(dbObj *gorm.DB, userID uint) ([]model.APIKey, error) {
+	keys := []model.APIKey{}
+	err := dbObj.Where("user_id = ?", userID).Order("id").Find(&keys).Error
+	return keys, err
+}
+
+// AddNewAPIKey は指定ユーザーのAPIキーを発行する
+// キー本体はここでのみ返却し、DBにはハッシュのみを保存する
+func AddNewAPIKey(dbObj *gorm.DB, userID uint, payload model.APIKeyPayload) (model.APIKey, string, error) {
+	key, prefix, hash, err := util.GenerateAPIKey()
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+	newKey := model.APIKey{
+		UserID:    userID,
+		Name:      payload.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scope:     payload.Scope,
+		ExpiresAt: toUTC(payload.ExpiresAt),
+		CreatedAt: dbNow(),
+	}
+	if err := dbObj.Create(&newKey).Error; err != nil {
+		return model.APIKey{}, "", err
+	}
+	return newKey, key, nil
+}
+
+// DeleteAPIKey は指定ユーザーのAPIキーを失効させる
+func DeleteAPIKey(dbObj *gorm.DB, userID uint, id uint) (model.APIKey, error) {
+	target := model.APIKey{}
+	if err := dbObj.Where("user_id = ?", userID).First(&target, id).Error; err != nil {
+		return model.APIKey{}, err
+	}
+	err := dbObj.Delete(&target).Error
+	return target, err
+}
+
+// CheckAPIKey は認証のmiddlewareで呼び出されるAPIキー認証用関数
+// 認証に成功した場合は対象のユーザーとAPIキーを返却する
+func CheckAPIKey(dbObj *gorm.DB, key string) (model.User, model.APIKey, bool) {
+	prefix, err := util.SplitAPIKeyPrefix(key)
+	if err != nil {
+		return model.User{}, model.APIKey{}, false
+	}
+	apiKey := model.APIKey{}
+	if err := dbObj.Where("prefix = ?", prefix).First(&apiKey).Error; err != nil {
+		return model.User{}, model.APIKey{}, false
+	}
+	if !util.CompareAPIKey(apiKey.KeyHash, key) {
+		return model.User{}, model.APIKey{}, false
+	}
+	now := dbNow()
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+		return model.User{}, model.APIKey{}, false
+	}
+	user, err := GetUserByID(dbObj, apiKey.UserID)
+	if err != nil {
+		return model.User{}, model.APIKey{}, false
+	}
+	dbObj.Model(&apiKey).Update("LastUsedAt", now)
+	return user, apiKey, true
+}
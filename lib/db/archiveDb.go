@@ -0,0 +1,36 @@
+package db
+
+import (
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+)
+
+// ArchiveCompleted は指定ユーザーのbeforeより前に完了したItemをまとめてアーカイブし、アーカイブしたItemのIDを返却する
+// 完了を表すStatusは指定ユーザーのワークフローに従う
+func ArchiveCompleted(dbObj *gorm.DB, userID uint, before time.Time) ([]uint, error) {
+	workflow, err := GetWorkflow(dbObj, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := []uint{}
+	err = dbObj.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Todo{}).Scopes(userScope(userID)).
+			Where("archived_at IS NULL AND status IN ? AND completed_at < ?", workflow.CompletedStates, before.UTC()).
+			Order("id").
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		now := dbNow()
+		return tx.Model(&model.Todo{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"ArchivedAt": now,
+			"UpdatedAt":  now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
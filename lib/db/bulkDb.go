@@ -0,0 +1,53 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+)
+
+// errBulkAborted は全ての操作を取り消す一括処理で、いずれかの操作が失敗した場合にトランザクションを取り消すためのエラー
+var errBulkAborted = errors.New("db: bulk operation aborted")
+
+// BulkTodo は指定ユーザーのItemへの複数の操作を1つのトランザクションで順に実行し、操作毎の結果を返却する
+// 各操作はセーブポイントで区切り、失敗した操作のみを取り消す
+// atomicがtrueの場合は1件でも失敗すると全ての操作を取り消し、以降の操作は実行しない
+func BulkTodo(dbObj *gorm.DB, userID uint, ops []model.BulkOperation, atomic bool) ([]model.BulkResult, error) {
+	results := make([]model.BulkResult, len(ops))
+	err := dbObj.Transaction(func(tx *gorm.DB) error {
+		for i, op := range ops {
+			var todo model.Todo
+			err := tx.Transaction(func(sp *gorm.DB) error {
+				var err error
+				todo, err = runBulkOperation(sp, userID, op)
+				return err
+			})
+			results[i] = model.BulkResult{Todo: todo, Err: err}
+			if err != nil && atomic {
+				return errBulkAborted
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkAborted) {
+		return nil, err
+	}
+	return results, nil
+}
+
+func runBulkOperation(dbObj *gorm.DB, userID uint, op model.BulkOperation) (model.Todo, error) {
+	switch op.Op {
+	case model.BulkCreate:
+		return AddNewTodo(dbObj, userID, op.Payload)
+	case model.BulkUpdate:
+		return UpdateItem(dbObj, userID, op.ID, op.Payload)
+	case model.BulkStatus:
+		return UpdateItemStatus(dbObj, userID, op.ID, op.Status)
+	case model.BulkDelete:
+		return DeleteItem(dbObj, userID, op.ID)
+	default:
+		return model.Todo{}, fmt.Errorf("db: unknown bulk operation %q", op.Op)
+	}
+}
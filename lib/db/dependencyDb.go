@@ -0,0 +1,127 @@
+package db
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidBlocker はBlockerに指定したTodoが存在しない場合のエラー
+	ErrInvalidBlocker = errors.New("db: blocker todo does not exist")
+	// ErrDependencyCycle はBlockerに指定したTodoが自身または自身の終了を待つTodoの場合のエラー
+	ErrDependencyCycle = errors.New("db: dependency would create a cycle")
+)
+
+// loadDependencies はTodoリストの各Itemに、終了を待つTodoと終了を待たれているTodoのIDを設定する
+func loadDependencies(dbObj *gorm.DB, todoList model.TodoList) error {
+	ids := make([]uint, 0, len(todoList))
+	for _, todo := range todoList {
+		ids = append(ids, todo.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	// Note: ゴミ箱のTodoとの依存関係は元に戻した場合のために残し、一覧には含めない
+	live := dbObj.Session(&gorm.Session{NewDB: true}).Model(&model.Todo{}).Select("id")
+	dependencies := []model.TodoDependency{}
+	if err := dbObj.Where("(todo_id IN ? AND blocker_id IN (?)) OR (blocker_id IN ? AND todo_id IN (?))", ids, live, ids, live).Find(&dependencies).Error; err != nil {
+		return err
+	}
+	blockedBy := map[uint][]uint{}
+	blocking := map[uint][]uint{}
+	for _, dependency := range dependencies {
+		blockedBy[dependency.TodoID] = append(blockedBy[dependency.TodoID], dependency.BlockerID)
+		blocking[dependency.BlockerID] = append(blocking[dependency.BlockerID], dependency.TodoID)
+	}
+	for i := range todoList {
+		todoList[i].BlockedBy = sortedIDs(blockedBy[todoList[i].ID])
+		todoList[i].Blocking = sortedIDs(blocking[todoList[i].ID])
+	}
+	return nil
+}
+
+func sortedIDs(ids []uint) []uint {
+	result := append([]uint{}, ids...)
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// checkBlockers は完了にするTodoに終了していないBlockerがないかを確認する
+// 終了していないBlockerがある場合は model.OpenBlockersError を返却する
+func checkBlockers(dbObj *gorm.DB, workflow model.Workflow, completing []uint) error {
+	blockers := model.TodoList{}
+	err := dbObj.Model(&model.Todo{}).
+		Select("todos.id", "todos.status").
+		Joins("JOIN todo_dependencies ON todo_dependencies.blocker_id = todos.id").
+		Where("todo_dependencies.todo_id IN ?", completing).
+		Find(&blockers).Error
+	if err != nil {
+		return err
+	}
+	if open := workflow.OpenBlockers(blockers, completing); len(open) > 0 {
+		return &model.OpenBlockersError{IDs: open}
+	}
+	return nil
+}
+
+// AddDependency は指定ユーザーのTodoが終了を待つTodo (Blocker) を追加する
+// 依存関係が循環する場合は ErrDependencyCycle を返却する。既に追加済みの場合は何もしない
+func AddDependency(dbObj *gorm.DB, userID uint, id uint, blockerID uint) (model.Todo, error) {
+	err := dbObj.Transaction(func(tx *gorm.DB) error {
+		target := model.Todo{}
+		if err := tx.Scopes(userScope(userID)).Select("id").First(&target, id).Error; err != nil {
+			return err
+		}
+		blocker := model.Todo{}
+		err := tx.Scopes(userScope(userID)).Select("id").First(&blocker, blockerID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidBlocker
+		}
+		if err != nil {
+			return err
+		}
+		dependencies := []model.TodoDependency{}
+		err = tx.Select("todo_dependencies.todo_id", "todo_dependencies.blocker_id").
+			Joins("JOIN todos ON todos.id = todo_dependencies.todo_id").
+			Where("todos.user_id = ?", userID).
+			Find(&dependencies).Error
+		if err != nil {
+			return err
+		}
+		blockers := map[uint][]uint{}
+		for _, dependency := range dependencies {
+			if dependency.TodoID == id && dependency.BlockerID == blockerID {
+				return nil
+			}
+			blockers[dependency.TodoID] = append(blockers[dependency.TodoID], dependency.BlockerID)
+		}
+		if model.DependsOn(blockers, blockerID, id) {
+			return ErrDependencyCycle
+		}
+		return tx.Create(&model.TodoDependency{TodoID: id, BlockerID: blockerID, CreatedAt: dbNow()}).Error
+	})
+	if err != nil {
+		return model.Todo{}, err
+	}
+	return GetTodoItemByID(dbObj, userID, id)
+}
+
+// RemoveDependency は指定ユーザーのTodoが終了を待つTodoを外す
+// 依存関係が存在しない場合は gorm.ErrRecordNotFound を返却する
+func RemoveDependency(dbObj *gorm.DB, userID uint, id uint, blockerID uint) (model.Todo, error) {
+	target := model.Todo{}
+	if err := dbObj.Scopes(userScope(userID)).Select("id").First(&target, id).Error; err != nil {
+		return model.Todo{}, err
+	}
+	result := dbObj.Where("todo_id = ? AND blocker_id = ?", id, blockerID).Delete(&model.TodoDependency{})
+	if result.Error != nil {
+		return model.Todo{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.Todo{}, gorm.ErrRecordNotFound
+	}
+	return GetTodoItemByID(dbObj, userID, id)
+}
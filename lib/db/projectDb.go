@@ -0,0 +1,145 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrProjectNameTaken は指定ユーザーに同じ名前のプロジェクトが既に存在する場合のエラー
+	ErrProjectNameTaken = errors.New("db: project name is already taken")
+	// ErrInvalidProject はTodoに指定したプロジェクトが存在しないかアーカイブ済みの場合のエラー
+	ErrInvalidProject = errors.New("db: project does not exist or is archived")
+)
+
+// projectScope はTodoリストを指定のプロジェクトのItemのみに絞り込む
+// 0の場合はプロジェクトに属さないItemのみとする
+func projectScope(projectID uint) func(*gorm.DB) *gorm.DB {
+	return func(dbObj *gorm.DB) *gorm.DB {
+		if projectID == 0 {
+			return dbObj.Where("project_id IS NULL")
+		}
+		return dbObj.Where("project_id = ?", projectID)
+	}
+}
+
+// checkTodoProject はTodoに設定するプロジェクトが指定ユーザーのアーカイブされていないプロジェクトかを確認する
+// 0はプロジェクトに属さないことを表すため、nilを返却する
+func checkTodoProject(dbObj *gorm.DB, userID uint, projectID uint) (*uint, error) {
+	if projectID == 0 {
+		return nil, nil
+	}
+	project := model.Project{}
+	err := dbObj.Where("user_id = ?", userID).First(&project, projectID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && project.Archived) {
+		return nil, ErrInvalidProject
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &project.ID, nil
+}
+
+func isProjectNameTaken(dbObj *gorm.DB, userID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := dbObj.Model(&model.Project{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetProjectList は指定ユーザーのプロジェクトの一覧を名前順で返却する
+// archivedがtrueの場合はアーカイブ済みのプロジェクトのみ、falseの場合はそれ以外を返却する
+func GetProjectList(dbObj *gorm.DB, userID uint, archived bool) ([]model.Project, error) {
+	projects := []model.Project{}
+	err := dbObj.Where("user_id = ? AND archived = ?", userID, archived).Order("name").Find(&projects).Error
+	return projects, err
+}
+
+// GetProjectByID はIDをもとに指定ユーザーのプロジェクトを取得する
+func GetProjectByID(dbObj *gorm.DB, userID uint, id uint) (model.Project, error) {
+	project := model.Project{}
+	err := dbObj.Where("user_id = ?", userID).First(&project, id).Error
+	return project, err
+}
+
+// AddNewProject は指定ユーザーのプロジェクトを作成する
+func AddNewProject(dbObj *gorm.DB, userID uint, payload model.ProjectPayload) (model.Project, error) {
+	taken, err := isProjectNameTaken(dbObj, userID, payload.Name, 0)
+	if err != nil {
+		return model.Project{}, err
+	}
+	if taken {
+		return model.Project{}, ErrProjectNameTaken
+	}
+	now := dbNow()
+	newProject := model.Project{
+		UserID:    userID,
+		Name:      payload.Name,
+		Color:     payload.Color,
+		Archived:  payload.Archived,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = dbObj.Create(&newProject).Error
+	return newProject, err
+}
+
+// UpdateProject は指定ユーザーのプロジェクトを部分的に更新する
+func UpdateProject(dbObj *gorm.DB, userID uint, id uint, update model.ProjectUpdate) (model.Project, error) {
+	target, err := GetProjectByID(dbObj, userID, id)
+	if err != nil {
+		return model.Project{}, err
+	}
+	if update.Name != nil {
+		taken, err := isProjectNameTaken(dbObj, userID, *update.Name, id)
+		if err != nil {
+			return model.Project{}, err
+		}
+		if taken {
+			return model.Project{}, ErrProjectNameTaken
+		}
+		target.Name = *update.Name
+	}
+	if update.Color != nil {
+		target.Color = *update.Color
+	}
+	if update.Archived != nil {
+		target.Archived = *update.Archived
+	}
+	target.UpdatedAt = dbNow()
+	err = dbObj.Model(&target).Select("Name", "Color", "Archived", "UpdatedAt").Updates(&target).Error
+	return target, err
+}
+
+// DeleteProject は指定ユーザーのプロジェクトを削除する
+// deleteTodosがtrueの場合はプロジェクトのItemをゴミ箱に移動し、falseの場合はプロジェクトに属さないItemに戻す
+func DeleteProject(dbObj *gorm.DB, userID uint, id uint, deleteTodos bool) (model.Project, error) {
+	target := model.Project{}
+	err := dbObj.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).First(&target, id).Error; err != nil {
+			return err
+		}
+		if deleteTodos {
+			ids := []uint{}
+			if err := tx.Model(&model.Todo{}).Scopes(userScope(userID), projectScope(id)).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			if err := detachChildren(tx, ids); err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", ids).Delete(&model.Todo{}).Error; err != nil {
+				return err
+			}
+		}
+		// Note: ゴミ箱のItemも含めてプロジェクトから外す
+		if err := tx.Unscoped().Model(&model.Todo{}).Scopes(userScope(userID), projectScope(id)).Updates(map[string]interface{}{
+			"ProjectID": nil,
+			"UpdatedAt": dbNow(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&target).Error
+	})
+	return target, err
+}
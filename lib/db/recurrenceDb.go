@@ -0,0 +1,42 @@
+package db
+
+import (
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+)
+
+// createNextOccurrence は繰り返しのTodoを完了に変更した場合に、次のTodoを作成する
+// 繰り返しのルールは次のTodoに引き継ぎ、完了したTodoからは外す
+func createNextOccurrence(dbObj *gorm.DB, workflow model.Workflow, target model.Todo, from string) error {
+	if !workflow.IsCompleted(target.Status) || target.Status == from || target.Recurrence == "" {
+		return nil
+	}
+	tags, err := getTodoTags(dbObj, []uint{target.ID})
+	if err != nil {
+		return err
+	}
+	target.Tags = tags[target.ID]
+	next, ok := workflow.NextOccurrence(target, target.UpdatedAt)
+	if ok {
+		if err := dbObj.Create(&next).Error; err != nil {
+			return err
+		}
+		if err := setTodoTags(dbObj, target.UserID, next.ID, next.Tags); err != nil {
+			return err
+		}
+	}
+	return dbObj.Model(&model.Todo{}).Where("id = ?", target.ID).Update("Recurrence", "").Error
+}
+
+// StopRecurrence は指定ユーザーのTodoの繰り返しを終了する
+// 完了しても次のTodoを作成しなくなる
+func StopRecurrence(dbObj *gorm.DB, userID uint, id uint) (model.Todo, error) {
+	target := model.Todo{}
+	if err := dbObj.Scopes(userScope(userID)).Select("id").First(&target, id).Error; err != nil {
+		return model.Todo{}, err
+	}
+	if err := dbObj.Model(&target).Update("Recurrence", "").Error; err != nil {
+		return model.Todo{}, err
+	}
+	return GetTodoItemByID(dbObj, userID, id)
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+)
+
+// searchDocument は全文検索の対象とする文書の式
+// migration の todos_search_idx と同じ式でなければインデックスが利用されない
+const searchDocument = "to_tsvector('simple', title || ' ' || details)"
+
+// headlineOptions は ts_headline で一致箇所を囲む文字列の指定
+// HTMLエスケープした後に HighlightStart 及び HighlightStop に置き換えるため、区切り文字を指定する
+const headlineOptions = `StartSel="` + model.HighlightStartSentinel + `", StopSel="` + model.HighlightStopSentinel + `"`
+
+// withoutSentinels は列の値から区切り文字を取り除くSQLの式を返却する
+// TitleやDetailsに含まれる区切り文字を一致箇所の区切りと誤認しないよう、ts_headline に渡す前に取り除く
+func withoutSentinels(column string) string {
+	return fmt.Sprintf("replace(replace(%s, chr(%d), ''), chr(%d), '')",
+		column, model.HighlightStartSentinel[0], model.HighlightStopSentinel[0])
+}
+
+// SearchTodo は指定ユーザーのTodoをTitleとDetailsから検索し、関連度の高い順に返却する関数
+// PostgreSQLでは全文検索を利用し、他のDBではLIKEで検索する
+func SearchTodo(dbObj *gorm.DB, userID uint, query string, limit int) ([]model.TodoSearchResult, error) {
+	if len(model.SearchTerms(query)) == 0 {
+		return []model.TodoSearchResult{}, nil
+	}
+	var results []model.TodoSearchResult
+	var err error
+	if dbObj.Dialector.Name() == "postgres" {
+		results, err = searchTodoFullText(dbObj, userID, query, limit)
+	} else {
+		results, err = searchTodoLike(dbObj, userID, query, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Note: 一覧と同じくタグ、進捗及び依存関係を読み込む
+	todoList := make(model.TodoList, 0, len(results))
+	for _, result := range results {
+		todoList = append(todoList, result.Todo)
+	}
+	if err := loadDetails(dbObj, userID, todoList); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Todo = todoList[i]
+	}
+	return results, nil
+}
+
+func searchTodoFullText(dbObj *gorm.DB, userID uint, query string, limit int) ([]model.TodoSearchResult, error) {
+	results := []model.TodoSearchResult{}
+	err := dbObj.Raw(`SELECT todos.*,
+		ts_rank(`+searchDocument+`, q) AS rank,
+		ts_headline('simple', `+withoutSentinels("title")+`, q, ?) AS title_snippet,
+		ts_headline('simple', `+withoutSentinels("details")+`, q, ?) AS details_snippet
+		FROM todos, plainto_tsquery('simple', ?) AS q
+		WHERE user_id = ? AND deleted_at IS NULL AND `+searchDocument+` @@ q
+		ORDER BY rank DESC, id ASC
+		LIMIT ?`,
+		headlineOptions+", HighlightAll=true", headlineOptions, query, userID, limit,
+	).Scan(&results).Error
+	for i := range results {
+		results[i].TitleSnippet = model.EscapeHighlight(results[i].TitleSnippet)
+		results[i].DetailsSnippet = model.EscapeHighlight(results[i].DetailsSnippet)
+	}
+	return results, err
+}
+
+func searchTodoLike(dbObj *gorm.DB, userID uint, query string, limit int) ([]model.TodoSearchResult, error) {
+	candidates := model.TodoList{}
+	dbObj = dbObj.Scopes(userScope(userID))
+	for _, term := range model.SearchTerms(query) {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(term)) + "%"
+		dbObj = dbObj.Where("(LOWER(title) LIKE ? ESCAPE '!' OR LOWER(details) LIKE ? ESCAPE '!')", pattern, pattern)
+	}
+	if err := dbObj.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	return model.RankTodoSearch(candidates, query, limit), nil
+}
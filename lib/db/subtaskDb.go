@@ -0,0 +1,148 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidParent はTodoに指定した親が存在しない場合のエラー
+	ErrInvalidParent = errors.New("db: parent todo does not exist")
+	// ErrParentCycle はTodoに指定した親が自身または自身の子孫の場合のエラー
+	ErrParentCycle = errors.New("db: parent todo would create a cycle")
+)
+
+// parentScope はTodoリストを指定のTodoの子のItemのみに絞り込む
+// 0の場合は親を持たないItemのみとする
+func parentScope(parentID uint) func(*gorm.DB) *gorm.DB {
+	return func(dbObj *gorm.DB) *gorm.DB {
+		if parentID == 0 {
+			return dbObj.Where("parent_id IS NULL")
+		}
+		return dbObj.Where("parent_id = ?", parentID)
+	}
+}
+
+// checkTodoParent はTodoに設定する親が指定ユーザーのTodoで、親子関係が循環しないかを確認する
+// idは新規作成の場合は0とする。0の親はTodoが親を持たないことを表すため、nilを返却する
+func checkTodoParent(dbObj *gorm.DB, userID uint, id uint, parentID uint) (*uint, error) {
+	if parentID == 0 {
+		return nil, nil
+	}
+	parent := model.Todo{}
+	err := dbObj.Scopes(userScope(userID)).Select("id").First(&parent, parentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidParent
+	}
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return &parent.ID, nil
+	}
+	rows := []model.Todo{}
+	if err := dbObj.Scopes(userScope(userID)).Select("id", "parent_id").Where("parent_id IS NOT NULL").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	parents := map[uint]uint{}
+	for _, row := range rows {
+		parents[row.ID] = *row.ParentID
+	}
+	if model.HasAncestor(parents, parentID, id) {
+		return nil, ErrParentCycle
+	}
+	return &parent.ID, nil
+}
+
+// getDescendants は指定のTodoの全ての子孫を取得する
+func getDescendants(dbObj *gorm.DB, userID uint, id uint) (model.TodoList, error) {
+	descendants := model.TodoList{}
+	parentIDs := []uint{id}
+	for len(parentIDs) > 0 {
+		children := model.TodoList{}
+		if err := dbObj.Scopes(userScope(userID)).Where("parent_id IN ?", parentIDs).Order("id").Find(&children).Error; err != nil {
+			return nil, err
+		}
+		parentIDs = []uint{}
+		for _, child := range children {
+			parentIDs = append(parentIDs, child.ID)
+		}
+		descendants = append(descendants, children...)
+	}
+	return descendants, nil
+}
+
+// completeDescendants はTodoを完了に変更した場合に、未完了の子孫を処理する
+// 繰り返しの子孫を完了にした場合は、その次のTodoも作成する
+// 未完了の子孫を完了できない場合は model.OpenChildrenError を、
+// 完了にするTodoに終了していないBlockerがある場合は model.OpenBlockersError を返却する
+func completeDescendants(dbObj *gorm.DB, workflow model.Workflow, target model.Todo, from string, complete bool) error {
+	if !workflow.IsCompleted(target.Status) || target.Status == from {
+		return nil
+	}
+	descendants, err := getDescendants(dbObj, target.UserID, target.ID)
+	if err != nil {
+		return err
+	}
+	changes, err := workflow.CompleteDescendants(descendants, target.Status, complete, target.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	completing := []uint{target.ID}
+	for _, change := range changes {
+		completing = append(completing, change.Todo.ID)
+	}
+	if err := checkBlockers(dbObj, workflow, completing); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if err := saveTransition(dbObj, change.Todo, change.From, map[string]interface{}{}); err != nil {
+			return err
+		}
+		if err := createNextOccurrence(dbObj, workflow, change.Todo, change.From); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadProgress はTodoリストの子を持つ各Itemに、子のStatusから計算した完了率を設定する
+func loadProgress(dbObj *gorm.DB, userID uint, todoList model.TodoList) error {
+	ids := make([]uint, 0, len(todoList))
+	for _, todo := range todoList {
+		ids = append(ids, todo.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	children := model.TodoList{}
+	if err := dbObj.Scopes(userScope(userID)).Select("parent_id", "status").Where("parent_id IN ?", ids).Find(&children).Error; err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	workflow, err := GetWorkflow(dbObj, userID)
+	if err != nil {
+		return err
+	}
+	statuses := map[uint][]string{}
+	for _, child := range children {
+		statuses[*child.ParentID] = append(statuses[*child.ParentID], child.Status)
+	}
+	for i := range todoList {
+		todoList[i].Progress = workflow.Progress(statuses[todoList[i].ID])
+	}
+	return nil
+}
+
+// detachChildren は指定のTodoの子を、ゴミ箱の子も含めて親を持たないTodoにする
+// ゴミ箱への移動では外部キー制約が働かないため、Todoを削除する前に呼び出す
+func detachChildren(dbObj *gorm.DB, parentIDs []uint) error {
+	if len(parentIDs) == 0 {
+		return nil
+	}
+	return dbObj.Unscoped().Model(&model.Todo{}).Where("parent_id IN ?", parentIDs).Update("ParentID", nil).Error
+}
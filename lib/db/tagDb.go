@@ -0,0 +1,172 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+)
+
+// ErrTagNameTaken は指定ユーザーに同じ名前のタグが既に存在する場合のエラー
+var ErrTagNameTaken = errors.New("db: tag name is already taken")
+
+// tagScope はTodoリストを指定のタグが設定されたItemのみに絞り込む
+// matchAllがtrueの場合は全てのタグが設定されたItemのみとする
+func tagScope(names []string, matchAll bool) func(*gorm.DB) *gorm.DB {
+	return func(dbObj *gorm.DB) *gorm.DB {
+		sub := dbObj.Session(&gorm.Session{NewDB: true}).
+			Table("todo_tags").
+			Select("todo_tags.todo_id").
+			Joins("JOIN tags ON tags.id = todo_tags.tag_id").
+			Where("tags.name IN ?", names)
+		if matchAll {
+			sub = sub.Group("todo_tags.todo_id").Having("COUNT(*) = ?", len(names))
+		}
+		return dbObj.Where("id IN (?)", sub)
+	}
+}
+
+// getTodoTags は指定のTodoに設定されたタグ名をTodoのID毎に名前順で返却する
+func getTodoTags(dbObj *gorm.DB, todoIDs []uint) (map[uint][]string, error) {
+	result := map[uint][]string{}
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+	rows := []struct {
+		TodoID uint
+		Name   string
+	}{}
+	err := dbObj.Table("todo_tags").
+		Select("todo_tags.todo_id, tags.name").
+		Joins("JOIN tags ON tags.id = todo_tags.tag_id").
+		Where("todo_tags.todo_id IN ?", todoIDs).
+		Order("tags.name").
+		Scan(&rows).Error
+	for _, row := range rows {
+		result[row.TodoID] = append(result[row.TodoID], row.Name)
+	}
+	return result, err
+}
+
+// loadTags はTodoリストの各Itemにタグ名を設定する
+func loadTags(dbObj *gorm.DB, todoList model.TodoList) error {
+	ids := make([]uint, 0, len(todoList))
+	for _, todo := range todoList {
+		ids = append(ids, todo.ID)
+	}
+	tags, err := getTodoTags(dbObj, ids)
+	if err != nil {
+		return err
+	}
+	for i := range todoList {
+		todoList[i].Tags = tags[todoList[i].ID]
+		if todoList[i].Tags == nil {
+			todoList[i].Tags = []string{}
+		}
+	}
+	return nil
+}
+
+// setTodoTags は指定のTodoのタグを置き換える
+// 存在しないタグは指定ユーザーのタグとして作成する
+func setTodoTags(dbObj *gorm.DB, userID uint, todoID uint, names []string) error {
+	names, err := model.NormalizeTagNames(names)
+	if err != nil {
+		return err
+	}
+	if err := dbObj.Where("todo_id = ?", todoID).Delete(&model.TodoTag{}).Error; err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	existing := []model.Tag{}
+	if err := dbObj.Where("user_id = ? AND name IN ?", userID, names).Find(&existing).Error; err != nil {
+		return err
+	}
+	ids := map[string]uint{}
+	for _, tag := range existing {
+		ids[tag.Name] = tag.ID
+	}
+	links := []model.TodoTag{}
+	for _, name := range names {
+		if _, ok := ids[name]; !ok {
+			tag := model.Tag{UserID: userID, Name: name, CreatedAt: dbNow()}
+			if err := dbObj.Create(&tag).Error; err != nil {
+				return err
+			}
+			ids[name] = tag.ID
+		}
+		links = append(links, model.TodoTag{TodoID: todoID, TagID: ids[name]})
+	}
+	return dbObj.Create(&links).Error
+}
+
+func isTagNameTaken(dbObj *gorm.DB, userID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := dbObj.Model(&model.Tag{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetTagList は指定ユーザーのタグの一覧を、各タグが設定されたTodoの件数と共に名前順で返却する
+// ゴミ箱のTodoは件数に含めない
+func GetTagList(dbObj *gorm.DB, userID uint) ([]model.TagCount, error) {
+	tags := []model.TagCount{}
+	err := dbObj.Model(&model.Tag{}).
+		Select("tags.*, COUNT(todos.id) AS count").
+		Joins("LEFT JOIN todo_tags ON todo_tags.tag_id = tags.id").
+		Joins("LEFT JOIN todos ON todos.id = todo_tags.todo_id AND todos.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id").
+		Order("tags.name").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// AddNewTag は指定ユーザーのタグを作成する
+func AddNewTag(dbObj *gorm.DB, userID uint, name string) (model.Tag, error) {
+	taken, err := isTagNameTaken(dbObj, userID, name, 0)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	if taken {
+		return model.Tag{}, ErrTagNameTaken
+	}
+	newTag := model.Tag{UserID: userID, Name: name, CreatedAt: dbNow()}
+	err = dbObj.Create(&newTag).Error
+	return newTag, err
+}
+
+// RenameTag は指定ユーザーのタグの名前を変更する
+// Todoとの関連はタグのIDで保持するため、設定済みの全てのTodoに反映される
+func RenameTag(dbObj *gorm.DB, userID uint, id uint, name string) (model.Tag, error) {
+	target := model.Tag{}
+	if err := dbObj.Where("user_id = ?", userID).First(&target, id).Error; err != nil {
+		return model.Tag{}, err
+	}
+	taken, err := isTagNameTaken(dbObj, userID, name, id)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	if taken {
+		return model.Tag{}, ErrTagNameTaken
+	}
+	target.Name = name
+	err = dbObj.Model(&target).Update("Name", name).Error
+	return target, err
+}
+
+// DeleteTag は指定ユーザーのタグを削除し、全てのTodoから外す
+func DeleteTag(dbObj *gorm.DB, userID uint, id uint) (model.Tag, error) {
+	target := model.Tag{}
+	err := dbObj.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).First(&target, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&model.TodoTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&target).Error
+	})
+	return target, err
+}
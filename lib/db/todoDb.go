@@ -1,6 +1,9 @@
 package db
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Z-me/practice-todo-api/api/model"
@@ -11,102 +14,402 @@ import (
 // GetNextID は次に指定するIDを取得する
 func GetNextID(dbObj *gorm.DB) uint {
 	todo := model.Todo{}
-	dbObj.Last(&todo)
+	dbObj.Unscoped().Last(&todo)
 	return todo.ID + 1
 }
 
-// GetTodoList DBからTodoリストを取得して返却する関数
-func GetTodoList(dbObj *gorm.DB) (model.TodoList, error) {
+// userScope は指定のユーザーが所有するItemのみに絞り込む
+func userScope(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(dbObj *gorm.DB) *gorm.DB {
+		return dbObj.Where("user_id = ?", userID)
+	}
+}
+
+// likeEscaper はLIKEのパターンの特殊文字をエスケープする
+// バックスラッシュの扱いがDB毎に異なるため "!" をエスケープ文字に利用する
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// filterScope はTodoリストを絞り込み条件に一致するItemのみに絞り込む
+func filterScope(filter model.TodoFilter) func(*gorm.DB) *gorm.DB {
+	return func(dbObj *gorm.DB) *gorm.DB {
+		if len(filter.Statuses) > 0 {
+			dbObj = dbObj.Where("status IN ?", filter.Statuses)
+		}
+		if len(filter.Priorities) > 0 {
+			dbObj = dbObj.Where("priority IN ?", filter.Priorities)
+		}
+		if filter.CreatedAfter != nil {
+			dbObj = dbObj.Where("created_at >= ?", filter.CreatedAfter.UTC())
+		}
+		if filter.CreatedBefore != nil {
+			dbObj = dbObj.Where("created_at < ?", filter.CreatedBefore.UTC())
+		}
+		if filter.UpdatedAfter != nil {
+			dbObj = dbObj.Where("updated_at >= ?", filter.UpdatedAfter.UTC())
+		}
+		if filter.UpdatedBefore != nil {
+			dbObj = dbObj.Where("updated_at < ?", filter.UpdatedBefore.UTC())
+		}
+		if filter.DueAfter != nil {
+			dbObj = dbObj.Where("due_at >= ?", filter.DueAfter.UTC())
+		}
+		if filter.DueBefore != nil {
+			dbObj = dbObj.Where("due_at < ?", filter.DueBefore.UTC())
+		}
+		if len(filter.ExcludeStatuses) > 0 {
+			dbObj = dbObj.Where("status NOT IN ?", filter.ExcludeStatuses)
+		}
+		if filter.ProjectID != nil {
+			dbObj = dbObj.Scopes(projectScope(*filter.ProjectID))
+		}
+		if filter.ParentID != nil {
+			dbObj = dbObj.Scopes(parentScope(*filter.ParentID))
+		}
+		if len(filter.Tags) > 0 {
+			dbObj = dbObj.Scopes(tagScope(filter.Tags, filter.TagsMatchAll))
+		}
+		if filter.Title != "" {
+			pattern := "%" + likeEscaper.Replace(strings.ToLower(filter.Title)) + "%"
+			dbObj = dbObj.Where("LOWER(title) LIKE ? ESCAPE '!'", pattern)
+		}
+		if filter.Archived {
+			dbObj = dbObj.Where("archived_at IS NOT NULL")
+		} else {
+			dbObj = dbObj.Where("archived_at IS NULL")
+		}
+		return dbObj
+	}
+}
+
+// priorityRank は priority を model.Priority.Rank と同じ優先度の高さに変換するSQLの式
+var priorityRank = func() string {
+	whens := []string{}
+	for _, p := range model.Priorities() {
+		whens = append(whens, fmt.Sprintf("WHEN '%s' THEN %d", p, p.Rank()))
+	}
+	return "(CASE priority " + strings.Join(whens, " ") + " ELSE 0 END)"
+}()
+
+// sortColumn は並び替え項目に対応するSQLの式を返却する
+func sortColumn(field string) string {
+	if field == "priority" {
+		return priorityRank
+	}
+	return field
+}
+
+// cursorValue はカーソルのItemの並び替え項目の値を、DBの値と比較できる形式で返却する
+func cursorValue(after *model.Todo, field string) interface{} {
+	value := after.FieldValue(field)
+	if t, ok := value.(time.Time); ok {
+		return t.UTC()
+	}
+	return value
+}
+
+// pageScope はTodoリストを並び替え、カーソル以降の指定件数に絞り込む
+// カーソルは直前のページ末尾のItemの値で表すため、行の追加や削除があってもページがずれない
+func pageScope(page model.TodoPage) func(*gorm.DB) *gorm.DB {
+	return func(dbObj *gorm.DB) *gorm.DB {
+		if page.After != nil {
+			conditions := []string{}
+			args := []interface{}{}
+			for i, key := range page.Sort {
+				terms := []string{}
+				for _, prev := range page.Sort[:i] {
+					terms = append(terms, sortColumn(prev.Field)+" = ?")
+					args = append(args, cursorValue(page.After, prev.Field))
+				}
+				if key.Desc {
+					terms = append(terms, sortColumn(key.Field)+" < ?")
+				} else {
+					terms = append(terms, sortColumn(key.Field)+" > ?")
+				}
+				args = append(args, cursorValue(page.After, key.Field))
+				conditions = append(conditions, "("+strings.Join(terms, " AND ")+")")
+			}
+			dbObj = dbObj.Where(strings.Join(conditions, " OR "), args...)
+		}
+		for _, key := range page.Sort {
+			if key.Desc {
+				dbObj = dbObj.Order(sortColumn(key.Field) + " DESC")
+			} else {
+				dbObj = dbObj.Order(sortColumn(key.Field) + " ASC")
+			}
+		}
+		if page.Limit > 0 {
+			dbObj = dbObj.Limit(page.Limit)
+		}
+		return dbObj
+	}
+}
+
+// GetTodoList DBから指定ユーザーの絞り込み条件に一致するTodoリストを取得して返却する関数
+func GetTodoList(dbObj *gorm.DB, userID uint, filter model.TodoFilter, page model.TodoPage) (model.TodoList, error) {
 	todoList := model.TodoList{}
-	err := dbObj.Find(&todoList).Error
+	if err := dbObj.Scopes(userScope(userID), filterScope(filter), pageScope(page)).Find(&todoList).Error; err != nil {
+		return nil, err
+	}
+	err := loadDetails(dbObj, userID, todoList)
 	return todoList, err
 }
 
-// GetTodoItemByID はIDをもとにItemを取得する関数
-func GetTodoItemByID(dbObj *gorm.DB, id uint) (model.Todo, error) {
+// GetScheduledTodoList DBから指定ユーザーの絞り込み条件に一致する期限のあるTodoリストを期限の近い順に取得して返却する関数
+func GetScheduledTodoList(dbObj *gorm.DB, userID uint, filter model.TodoFilter, page model.SchedulePage) (model.TodoList, error) {
+	query := dbObj.Scopes(userScope(userID), filterScope(filter)).Where("due_at IS NOT NULL")
+	if page.After != nil && page.After.DueAt != nil {
+		dueAt := page.After.DueAt.UTC()
+		query = query.Where("(due_at > ? OR (due_at = ? AND id > ?))", dueAt, dueAt, page.After.ID)
+	}
+	if page.Limit > 0 {
+		query = query.Limit(page.Limit)
+	}
+	todoList := model.TodoList{}
+	if err := query.Order("due_at ASC").Order("id ASC").Find(&todoList).Error; err != nil {
+		return nil, err
+	}
+	err := loadDetails(dbObj, userID, todoList)
+	return todoList, err
+}
+
+// GetTodoItemByID はIDをもとに指定ユーザーのItemを取得する関数
+func GetTodoItemByID(dbObj *gorm.DB, userID uint, id uint) (model.Todo, error) {
 	todo := model.Todo{}
-	err := dbObj.First(&todo, id).Error
-	return todo, err
+	if err := dbObj.Scopes(userScope(userID)).First(&todo, id).Error; err != nil {
+		return model.Todo{}, err
+	}
+	todoList := model.TodoList{todo}
+	err := loadDetails(dbObj, userID, todoList)
+	return todoList[0], err
+}
+
+// loadDetails はTodoリストの各Itemに、タグ、子の完了率及び依存関係を設定する
+func loadDetails(dbObj *gorm.DB, userID uint, todoList model.TodoList) error {
+	if err := loadTags(dbObj, todoList); err != nil {
+		return err
+	}
+	if err := loadProgress(dbObj, userID, todoList); err != nil {
+		return err
+	}
+	return loadDependencies(dbObj, todoList)
+}
+
+// 日時は全てUTCで保存し、比較に利用する値もUTCに揃える
+// SQLiteは日時をタイムゾーン付きの文字列として保存して比較するため、タイムゾーンが混在すると正しく比較できない
+
+// dbNow はDBに保存する現在日時を返却する
+func dbNow() time.Time {
+	return time.Now().UTC()
 }
 
-// AddNewTodo はDBに指定のPayloadの値を投入
-func AddNewTodo(dbObj *gorm.DB, payload model.Payload) (model.Todo, error) {
+// toUTC は日時をUTCに揃える
+func toUTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// ErrConcurrentUpdate は更新中に他のリクエストでStatusが変更された場合のエラー
+var ErrConcurrentUpdate = errors.New("db: item was modified concurrently")
+
+// AddNewTodo はDBに指定のPayloadの値を指定ユーザーのItemとして投入
+// Statusは指定ユーザーのワークフローに定義されたものでなければならない
+func AddNewTodo(dbObj *gorm.DB, userID uint, payload model.Payload) (model.Todo, error) {
+	workflow, err := GetWorkflow(dbObj, userID)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	var projectID *uint
+	if payload.ProjectID != nil {
+		if projectID, err = checkTodoProject(dbObj, userID, *payload.ProjectID); err != nil {
+			return model.Todo{}, err
+		}
+	}
+	var parentID *uint
+	if payload.ParentID != nil {
+		if parentID, err = checkTodoParent(dbObj, userID, 0, *payload.ParentID); err != nil {
+			return model.Todo{}, err
+		}
+	}
+	now := dbNow()
 	newTodo := model.Todo{
+		UserID:    userID,
+		ProjectID: projectID,
+		ParentID:  parentID,
 		Title:     payload.Title,
-		Status:    payload.Status,
 		Details:   payload.Details,
 		Priority:  payload.Priority,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		StartAt:   toUTC(payload.StartAt),
+		DueAt:     toUTC(payload.DueAt),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
-	result := dbObj.Create(&newTodo)
-
-	return model.Todo{
-		ID:        newTodo.ID,
-		Title:     newTodo.Title,
-		Status:    newTodo.Status,
-		Details:   newTodo.Details,
-		Priority:  newTodo.Priority,
-		CreatedAt: newTodo.CreatedAt,
-		UpdatedAt: newTodo.UpdatedAt,
-	}, result.Error
+	if payload.Recurrence != nil {
+		newTodo.Recurrence = *payload.Recurrence
+		newTodo.Timezone = payload.Timezone
+	}
+	if payload.Archived != nil {
+		model.SetArchived(&newTodo, *payload.Archived, now)
+	}
+	if err := workflow.Apply(&newTodo, payload.Status, now); err != nil {
+		return model.Todo{}, err
+	}
+	err = dbObj.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newTodo).Error; err != nil {
+			return err
+		}
+		return setTodoTags(tx, userID, newTodo.ID, payload.Tags)
+	})
+	if err != nil {
+		return model.Todo{}, err
+	}
+	return GetTodoItemByID(dbObj, userID, newTodo.ID)
 }
 
-// UpdateItem はDB上から指定のItemの情報を更新
-func UpdateItem(dbObj *gorm.DB, id uint, payload model.Payload) (model.Todo, error) {
+// saveTransition はワークフローに従ってStatusを変更したItemを保存する
+// 読み込み後に他のリクエストでStatusが変更されていた場合は ErrConcurrentUpdate を返却する
+func saveTransition(dbObj *gorm.DB, target model.Todo, from string, values map[string]interface{}) error {
+	values["Status"] = target.Status
+	values["StartedAt"] = target.StartedAt
+	values["CompletedAt"] = target.CompletedAt
+	values["UpdatedAt"] = target.UpdatedAt
+	result := dbObj.Model(&model.Todo{}).
+		Where("id = ? AND user_id = ? AND status = ?", target.ID, target.UserID, from).
+		Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentUpdate
+	}
+	return nil
+}
 
+// UpdateItem はDB上から指定ユーザーの指定のItemの情報を更新
+// Statusの変更はワークフローで許可された遷移のみ行える
+// 未完了の子孫を持つItemを完了にする場合は payload.CompleteChildren に従う
+// 繰り返しのItemを完了にした場合は次のItemを作成する
+func UpdateItem(dbObj *gorm.DB, userID uint, id uint, payload model.Payload) (model.Todo, error) {
+	workflow, err := GetWorkflow(dbObj, userID)
+	if err != nil {
+		return model.Todo{}, err
+	}
 	target := model.Todo{}
-	if err := dbObj.First(&target, id).Error; err != nil {
+	if err := dbObj.Scopes(userScope(userID)).First(&target, id).Error; err != nil {
 		return model.Todo{}, err
 	}
 
-	target.ID = id
+	from := target.Status
 	target.Title = payload.Title
-	target.Status = payload.Status
 	target.Details = payload.Details
 	target.Priority = payload.Priority
-	target.UpdatedAt = time.Now()
-
-	if err := dbObj.Save(&target).Error; err != nil {
+	target.StartAt = toUTC(payload.StartAt)
+	target.DueAt = toUTC(payload.DueAt)
+	if payload.ProjectID != nil {
+		if target.ProjectID, err = checkTodoProject(dbObj, userID, *payload.ProjectID); err != nil {
+			return model.Todo{}, err
+		}
+	}
+	if payload.ParentID != nil {
+		if target.ParentID, err = checkTodoParent(dbObj, userID, id, *payload.ParentID); err != nil {
+			return model.Todo{}, err
+		}
+	}
+	if payload.Recurrence != nil {
+		target.Recurrence = *payload.Recurrence
+		target.Timezone = payload.Timezone
+	}
+	target.UpdatedAt = dbNow()
+	if payload.Archived != nil {
+		model.SetArchived(&target, *payload.Archived, target.UpdatedAt)
+	}
+	if err := workflow.Apply(&target, payload.Status, target.UpdatedAt); err != nil {
 		return model.Todo{}, err
 	}
 
-	err := dbObj.First(&target, id).Error
-	return target, err
+	err = dbObj.Transaction(func(tx *gorm.DB) error {
+		if err := saveTransition(tx, target, from, map[string]interface{}{
+			"Title":      target.Title,
+			"Details":    target.Details,
+			"Priority":   target.Priority,
+			"StartAt":    target.StartAt,
+			"DueAt":      target.DueAt,
+			"ProjectID":  target.ProjectID,
+			"ParentID":   target.ParentID,
+			"Recurrence": target.Recurrence,
+			"Timezone":   target.Timezone,
+			"ArchivedAt": target.ArchivedAt,
+		}); err != nil {
+			return err
+		}
+		if err := completeDescendants(tx, workflow, target, from, payload.CompleteChildren); err != nil {
+			return err
+		}
+		if payload.Tags != nil {
+			if err := setTodoTags(tx, userID, id, payload.Tags); err != nil {
+				return err
+			}
+		}
+		return createNextOccurrence(tx, workflow, target, from)
+	})
+	if err != nil {
+		return model.Todo{}, err
+	}
+	return GetTodoItemByID(dbObj, userID, id)
 }
 
-// UpdateItemStatus はDB上から指定のItemのStatusを更新
-func UpdateItemStatus(dbObj *gorm.DB, id uint, status model.Status) (model.Todo, error) {
+// UpdateItemStatus はDB上から指定ユーザーの指定のItemのStatusを更新
+// ワークフローで許可されていない遷移の場合は model.TransitionError を返却する
+// 未完了の子孫を持つItemを完了にする場合は status.CompleteChildren に従う
+// 繰り返しのItemを完了にした場合は次のItemを作成する
+func UpdateItemStatus(dbObj *gorm.DB, userID uint, id uint, status model.Status) (model.Todo, error) {
+	workflow, err := GetWorkflow(dbObj, userID)
+	if err != nil {
+		return model.Todo{}, err
+	}
 	target := model.Todo{}
-	if err := dbObj.First(&target, id).Error; err != nil {
+	if err := dbObj.Scopes(userScope(userID)).First(&target, id).Error; err != nil {
 		return model.Todo{}, err
 	}
 
-	if err := dbObj.Model(&target).Updates(map[string]interface{}{
-		"Status":    status.Status,
-		"UpdatedAt": time.Now(),
-	}).Error; err != nil {
+	from := target.Status
+	target.UpdatedAt = dbNow()
+	if err := workflow.Apply(&target, status.Status, target.UpdatedAt); err != nil {
 		return model.Todo{}, err
 	}
-
-	err := dbObj.First(&target, id).Error
-	return target, err
+	err = dbObj.Transaction(func(tx *gorm.DB) error {
+		if err := saveTransition(tx, target, from, map[string]interface{}{}); err != nil {
+			return err
+		}
+		if err := completeDescendants(tx, workflow, target, from, status.CompleteChildren); err != nil {
+			return err
+		}
+		return createNextOccurrence(tx, workflow, target, from)
+	})
+	if err != nil {
+		return model.Todo{}, err
+	}
+	return GetTodoItemByID(dbObj, userID, id)
 }
 
-// DeleteItem は任意のItemを削除
-func DeleteItem(dbObj *gorm.DB, id uint) (model.Todo, error) {
-	target := model.Todo{}
-	if err := dbObj.First(&target, id).Error; err != nil {
+// DeleteItem は指定ユーザーの任意のItemをゴミ箱に移動する
+// 削除したItemの子は親を持たないItemになる
+func DeleteItem(dbObj *gorm.DB, userID uint, id uint) (model.Todo, error) {
+	target, err := GetTodoItemByID(dbObj, userID, id)
+	if err != nil {
 		return model.Todo{}, err
 	}
 
-	result := model.Todo{
-		ID:       id,
-		Title:    target.Title,
-		Status:   target.Status,
-		Details:  target.Details,
-		Priority: target.Priority,
+	err = dbObj.Transaction(func(tx *gorm.DB) error {
+		if err := detachChildren(tx, []uint{id}); err != nil {
+			return err
+		}
+		return tx.Delete(&target).Error
+	})
+	if err != nil {
+		return model.Todo{}, err
 	}
-	err := dbObj.Delete(&target).Error
-	return result, err
+	return getTrashItem(dbObj, userID, id)
 }
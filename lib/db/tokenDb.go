@@ -0,0 +1,36 @@
+package db
+
+import (
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	_ "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokeToken は指定のトークンを失効済みとして登録し、登録した場合はtrueを返却する
+// 既に失効済みの場合は登録せずfalseを返却するため、同時に同じトークンを失効させても一方のみが成功する
+func RevokeToken(dbObj *gorm.DB, jti string, userID uint, expiresAt time.Time) (bool, error) {
+	result := dbObj.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt.UTC(),
+		CreatedAt: dbNow(),
+	})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsTokenRevoked は指定のトークンが失効済みかを確認する
+func IsTokenRevoked(dbObj *gorm.DB, jti string) (bool, error) {
+	var count int64
+	err := dbObj.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// PurgeRevokedTokens はbeforeより前に有効期限が切れた失効済みのトークンを削除し、削除した件数を返却する
+// 有効期限が切れたトークンは検証で拒否されるため、失効済みとして保持する必要がない
+func PurgeRevokedTokens(dbObj *gorm.DB, before time.Time) (int64, error) {
+	result := dbObj.Where("expires_at < ?", before.UTC()).Delete(&model.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+)
+
+// trashScope はゴミ箱のItemのみに絞り込む
+func trashScope(dbObj *gorm.DB) *gorm.DB {
+	return dbObj.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+// GetTrashList は指定ユーザーのゴミ箱のItemを削除日時の新しい順で取得する
+func GetTrashList(dbObj *gorm.DB, userID uint) (model.TodoList, error) {
+	todoList := model.TodoList{}
+	if err := dbObj.Scopes(trashScope, userScope(userID)).Order("deleted_at DESC, id DESC").Find(&todoList).Error; err != nil {
+		return nil, err
+	}
+	err := loadDetails(dbObj, userID, todoList)
+	return todoList, err
+}
+
+// getTrashItem はIDをもとに指定ユーザーのゴミ箱のItemを取得する
+func getTrashItem(dbObj *gorm.DB, userID uint, id uint) (model.Todo, error) {
+	todo := model.Todo{}
+	if err := dbObj.Scopes(trashScope, userScope(userID)).First(&todo, id).Error; err != nil {
+		return model.Todo{}, err
+	}
+	todoList := model.TodoList{todo}
+	err := loadDetails(dbObj, userID, todoList)
+	return todoList[0], err
+}
+
+// RestoreItem は指定ユーザーのゴミ箱のItemを元に戻す
+// 削除時に子は親を持たないItemになっているため、子は元に戻さない
+func RestoreItem(dbObj *gorm.DB, userID uint, id uint) (model.Todo, error) {
+	target, err := getTrashItem(dbObj, userID, id)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	if err := dbObj.Unscoped().Model(&target).Updates(map[string]interface{}{
+		"DeletedAt": nil,
+		"UpdatedAt": dbNow(),
+	}).Error; err != nil {
+		return model.Todo{}, err
+	}
+	return GetTodoItemByID(dbObj, userID, id)
+}
+
+// PurgeItem は指定ユーザーのゴミ箱のItemを完全に削除する
+// タグ及び依存関係は外部キー制約により削除される
+func PurgeItem(dbObj *gorm.DB, userID uint, id uint) (model.Todo, error) {
+	target, err := getTrashItem(dbObj, userID, id)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	err = dbObj.Unscoped().Delete(&target).Error
+	return target, err
+}
+
+// PurgeTrash はbeforeより前にゴミ箱に移動した全てのユーザーのItemを完全に削除し、削除した件数を返却する
+func PurgeTrash(dbObj *gorm.DB, before time.Time) (int64, error) {
+	result := dbObj.Unscoped().Where("deleted_at < ?", before.UTC()).Delete(&model.Todo{})
+	return result.RowsAffected, result.Error
+}
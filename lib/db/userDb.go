@@ -1,26 +1,146 @@
 package db
 
 import (
+	"errors"
+
 	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/lib/util"
 	_ "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// getUserID は認証に利用されたユーザーのIDを取得
-func getUserID(dbObj *gorm.DB, name string) uint {
+// ErrUserNameTaken は既に同じ名前のユーザーが存在する場合のエラー
+var ErrUserNameTaken = errors.New("user name is already taken")
+
+// GetUserByID はIDからユーザーを取得
+func GetUserByID(dbObj *gorm.DB, id uint) (model.User, error) {
 	user := model.User{}
-	dbObj.Where("name = ?", name).First(&user)
-	return user.ID
+	err := dbObj.First(&user, id).Error
+	return user, err
 }
 
-// CheckUserAuth は認証のmiddlewareで呼び出されるユーザー認証用関数
-func CheckUserAuth(dbObj *gorm.DB, name, password string) bool {
+// GetUserByName は認証に利用されたユーザーを名前から取得
+func GetUserByName(dbObj *gorm.DB, name string) (model.User, error) {
 	user := model.User{}
-	if result := dbObj.Where("name = ?", name).First(&user); result.Error != nil {
-		return false
+	err := dbObj.Where("name = ?", name).First(&user).Error
+	return user, err
+}
+
+// CheckUserAuth は認証のmiddlewareで呼び出されるユーザー認証用関数
+// 認証に成功した場合は対象のユーザーを返却する
+// 平文でパスワードが保存されている場合は、認証成功時にハッシュ化して保存し直す
+func CheckUserAuth(dbObj *gorm.DB, name, password string) (model.User, bool) {
+	user, err := GetUserByName(dbObj, name)
+	if err != nil {
+		util.CompareDummyPassword(password)
+		return model.User{}, false
+	}
+	if !util.ComparePassword(user.Password, password) {
+		return model.User{}, false
+	}
+	if !util.IsHashedPassword(user.Password) {
+		if err := rehashPassword(dbObj, &user, password); err != nil {
+			return model.User{}, false
+		}
 	}
-	if password != user.Password {
-		return false
+	return user, true
+}
+
+// rehashPassword は平文で保存されているパスワードをハッシュ化して保存し直す
+func rehashPassword(dbObj *gorm.DB, user *model.User, password string) error {
+	hashed, err := util.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := dbObj.Model(user).Updates(map[string]interface{}{
+		"Password":  hashed,
+		"UpdatedAt": dbNow(),
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// isUserNameTaken は指定の名前が他のユーザーに利用されているかを確認する
+func isUserNameTaken(dbObj *gorm.DB, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := dbObj.Model(&model.User{}).Where("name = ? AND id <> ?", name, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// AddNewUser はパスワードをハッシュ化して新規ユーザーを登録
+func AddNewUser(dbObj *gorm.DB, payload model.UserPayload) (model.User, error) {
+	taken, err := isUserNameTaken(dbObj, payload.Name, 0)
+	if err != nil {
+		return model.User{}, err
 	}
-	return true
+	if taken {
+		return model.User{}, ErrUserNameTaken
+	}
+	hashed, err := util.HashPassword(payload.Password)
+	if err != nil {
+		return model.User{}, err
+	}
+	newUser := model.User{
+		Name:      payload.Name,
+		Password:  hashed,
+		CreatedAt: dbNow(),
+		UpdatedAt: dbNow(),
+	}
+	err = dbObj.Create(&newUser).Error
+	return newUser, err
+}
+
+// UpdateUser は指定ユーザーの名前またはパスワードを更新
+// Payloadの空の項目は更新しない。パスワードを変更した場合は変更日時を記録する
+func UpdateUser(dbObj *gorm.DB, id uint, payload model.UserPayload) (model.User, error) {
+	target := model.User{}
+	if err := dbObj.First(&target, id).Error; err != nil {
+		return model.User{}, err
+	}
+
+	now := dbNow()
+	values := map[string]interface{}{
+		"UpdatedAt": now,
+	}
+	if payload.Name != "" && payload.Name != target.Name {
+		taken, err := isUserNameTaken(dbObj, payload.Name, id)
+		if err != nil {
+			return model.User{}, err
+		}
+		if taken {
+			return model.User{}, ErrUserNameTaken
+		}
+		values["Name"] = payload.Name
+	}
+	if payload.Password != "" {
+		hashed, err := util.HashPassword(payload.Password)
+		if err != nil {
+			return model.User{}, err
+		}
+		values["Password"] = hashed
+		values["PasswordChangedAt"] = now
+	}
+
+	if err := dbObj.Model(&target).Updates(values).Error; err != nil {
+		return model.User{}, err
+	}
+
+	err := dbObj.First(&target, id).Error
+	return target, err
+}
+
+// DeleteUser は指定ユーザーを削除
+// ゴミ箱のTodoも含めてユーザーのTodoも完全に削除する
+func DeleteUser(dbObj *gorm.DB, id uint) error {
+	return dbObj.Transaction(func(tx *gorm.DB) error {
+		target := model.User{}
+		if err := tx.First(&target, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&model.Todo{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&target).Error
+	})
 }
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"github.com/Z-me/practice-todo-api/api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetWorkflow は指定ユーザーのワークフローを取得する
+// ユーザーがワークフローを設定していない場合は既定のワークフローを返却する
+func GetWorkflow(dbObj *gorm.DB, userID uint) (model.Workflow, error) {
+	target := model.UserWorkflow{}
+	// Note: 多くのユーザーはワークフローを設定しないため、First で "record not found" のエラーログを出さないよう Find で取得する
+	result := dbObj.Where("user_id = ?", userID).Limit(1).Find(&target)
+	if result.Error != nil {
+		return model.Workflow{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.DefaultWorkflow(), nil
+	}
+	return target.Definition, nil
+}
+
+// SaveWorkflow は指定ユーザーのワークフローを登録または更新する
+func SaveWorkflow(dbObj *gorm.DB, userID uint, workflow model.Workflow) error {
+	return dbObj.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"definition", "updated_at"}),
+	}).Create(&model.UserWorkflow{
+		UserID:     userID,
+		Definition: workflow,
+		UpdatedAt:  dbNow(),
+	}).Error
+}
+
+// DeleteWorkflow は指定ユーザーのワークフローを削除し、既定のワークフローに戻す
+func DeleteWorkflow(dbObj *gorm.DB, userID uint) error {
+	return dbObj.Delete(&model.UserWorkflow{}, userID).Error
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/lib/db"
+	"github.com/Z-me/practice-todo-api/lib/util"
+	"gorm.io/gorm"
+)
+
+// NewGorm はgormのDB接続を利用するRepositoryの一式を生成する
+func NewGorm(dbObj *gorm.DB) Repositories {
+	return Repositories{
+		Todos:     &gormTodoRepository{db: dbObj},
+		Users:     &gormUserRepository{db: dbObj},
+		Tokens:    &gormTokenRepository{db: dbObj},
+		APIKeys:   &gormAPIKeyRepository{db: dbObj},
+		Workflows: &gormWorkflowRepository{db: dbObj},
+		Tags:      &gormTagRepository{db: dbObj},
+		Projects:  &gormProjectRepository{db: dbObj},
+		close: func() error {
+			return util.CloseDB(dbObj)
+		},
+	}
+}
+
+// translateError はgorm及びlib/dbのエラーをRepositoryのエラーに変換する
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, db.ErrUserNameTaken):
+		return ErrUserNameTaken
+	case errors.Is(err, db.ErrConcurrentUpdate):
+		return ErrConflict
+	case errors.Is(err, db.ErrTagNameTaken):
+		return ErrTagNameTaken
+	case errors.Is(err, db.ErrProjectNameTaken):
+		return ErrProjectNameTaken
+	case errors.Is(err, db.ErrInvalidProject):
+		return ErrInvalidProject
+	case errors.Is(err, db.ErrInvalidParent):
+		return ErrInvalidParent
+	case errors.Is(err, db.ErrParentCycle):
+		return ErrParentCycle
+	case errors.Is(err, db.ErrInvalidBlocker):
+		return ErrInvalidBlocker
+	case errors.Is(err, db.ErrDependencyCycle):
+		return ErrDependencyCycle
+	default:
+		return err
+	}
+}
+
+type gormTodoRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTodoRepository) GetTodoList(ctx context.Context, userID uint, filter model.TodoFilter, page model.TodoPage) (model.TodoList, error) {
+	todoList, err := db.GetTodoList(r.db.WithContext(ctx), userID, filter, page)
+	return todoList, translateError(err)
+}
+
+func (r *gormTodoRepository) GetScheduledTodoList(ctx context.Context, userID uint, filter model.TodoFilter, page model.SchedulePage) (model.TodoList, error) {
+	todoList, err := db.GetScheduledTodoList(r.db.WithContext(ctx), userID, filter, page)
+	return todoList, translateError(err)
+}
+
+func (r *gormTodoRepository) SearchTodo(ctx context.Context, userID uint, query string, limit int) ([]model.TodoSearchResult, error) {
+	results, err := db.SearchTodo(r.db.WithContext(ctx), userID, query, limit)
+	return results, translateError(err)
+}
+
+func (r *gormTodoRepository) GetTodoItemByID(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	todo, err := db.GetTodoItemByID(r.db.WithContext(ctx), userID, id)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) AddNewTodo(ctx context.Context, userID uint, payload model.Payload) (model.Todo, error) {
+	todo, err := db.AddNewTodo(r.db.WithContext(ctx), userID, payload)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) UpdateItem(ctx context.Context, userID uint, id uint, payload model.Payload) (model.Todo, error) {
+	todo, err := db.UpdateItem(r.db.WithContext(ctx), userID, id, payload)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) UpdateItemStatus(ctx context.Context, userID uint, id uint, status model.Status) (model.Todo, error) {
+	todo, err := db.UpdateItemStatus(r.db.WithContext(ctx), userID, id, status)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) DeleteItem(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	todo, err := db.DeleteItem(r.db.WithContext(ctx), userID, id)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) AddDependency(ctx context.Context, userID uint, id uint, blockerID uint) (model.Todo, error) {
+	todo, err := db.AddDependency(r.db.WithContext(ctx), userID, id, blockerID)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) RemoveDependency(ctx context.Context, userID uint, id uint, blockerID uint) (model.Todo, error) {
+	todo, err := db.RemoveDependency(r.db.WithContext(ctx), userID, id, blockerID)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) StopRecurrence(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	todo, err := db.StopRecurrence(r.db.WithContext(ctx), userID, id)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) GetTrashList(ctx context.Context, userID uint) (model.TodoList, error) {
+	todoList, err := db.GetTrashList(r.db.WithContext(ctx), userID)
+	return todoList, translateError(err)
+}
+
+func (r *gormTodoRepository) RestoreItem(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	todo, err := db.RestoreItem(r.db.WithContext(ctx), userID, id)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) PurgeItem(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	todo, err := db.PurgeItem(r.db.WithContext(ctx), userID, id)
+	return todo, translateError(err)
+}
+
+func (r *gormTodoRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	count, err := db.PurgeTrash(r.db.WithContext(ctx), before)
+	return count, translateError(err)
+}
+
+func (r *gormTodoRepository) ArchiveCompleted(ctx context.Context, userID uint, before time.Time) ([]uint, error) {
+	ids, err := db.ArchiveCompleted(r.db.WithContext(ctx), userID, before)
+	return ids, translateError(err)
+}
+
+func (r *gormTodoRepository) Bulk(ctx context.Context, userID uint, ops []model.BulkOperation, atomic bool) ([]model.BulkResult, error) {
+	results, err := db.BulkTodo(r.db.WithContext(ctx), userID, ops, atomic)
+	for i := range results {
+		results[i].Err = translateError(results[i].Err)
+	}
+	return results, translateError(err)
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) CheckUserAuth(ctx context.Context, name, password string) (model.User, bool) {
+	return db.CheckUserAuth(r.db.WithContext(ctx), name, password)
+}
+
+func (r *gormUserRepository) GetUserByID(ctx context.Context, id uint) (model.User, error) {
+	user, err := db.GetUserByID(r.db.WithContext(ctx), id)
+	return user, translateError(err)
+}
+
+func (r *gormUserRepository) GetUserByName(ctx context.Context, name string) (model.User, error) {
+	user, err := db.GetUserByName(r.db.WithContext(ctx), name)
+	return user, translateError(err)
+}
+
+func (r *gormUserRepository) AddNewUser(ctx context.Context, payload model.UserPayload) (model.User, error) {
+	user, err := db.AddNewUser(r.db.WithContext(ctx), payload)
+	return user, translateError(err)
+}
+
+func (r *gormUserRepository) UpdateUser(ctx context.Context, id uint, payload model.UserPayload) (model.User, error) {
+	user, err := db.UpdateUser(r.db.WithContext(ctx), id, payload)
+	return user, translateError(err)
+}
+
+func (r *gormUserRepository) DeleteUser(ctx context.Context, id uint) error {
+	return translateError(db.DeleteUser(r.db.WithContext(ctx), id))
+}
+
+type gormTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTokenRepository) RevokeToken(ctx context.Context, jti string, userID uint, expiresAt time.Time) (bool, error) {
+	revoked, err := db.RevokeToken(r.db.WithContext(ctx), jti, userID, expiresAt)
+	return revoked, translateError(err)
+}
+
+func (r *gormTokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	revoked, err := db.IsTokenRevoked(r.db.WithContext(ctx), jti)
+	return revoked, translateError(err)
+}
+
+func (r *gormTokenRepository) PurgeRevokedTokens(ctx context.Context, before time.Time) (int64, error) {
+	count, err := db.PurgeRevokedTokens(r.db.WithContext(ctx), before)
+	return count, translateError(err)
+}
+
+type gormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormAPIKeyRepository) GetAPIKeyList(ctx context.Context, userID uint) ([]model.APIKey, error) {
+	keys, err := db.GetAPIKeyList(r.db.WithContext(ctx), userID)
+	return keys, translateError(err)
+}
+
+func (r *gormAPIKeyRepository) AddNewAPIKey(ctx context.Context, userID uint, payload model.APIKeyPayload) (model.APIKey, string, error) {
+	apiKey, key, err := db.AddNewAPIKey(r.db.WithContext(ctx), userID, payload)
+	return apiKey, key, translateError(err)
+}
+
+func (r *gormAPIKeyRepository) DeleteAPIKey(ctx context.Context, userID uint, id uint) (model.APIKey, error) {
+	apiKey, err := db.DeleteAPIKey(r.db.WithContext(ctx), userID, id)
+	return apiKey, translateError(err)
+}
+
+func (r *gormAPIKeyRepository) CheckAPIKey(ctx context.Context, key string) (model.User, model.APIKey, bool) {
+	return db.CheckAPIKey(r.db.WithContext(ctx), key)
+}
+
+type gormWorkflowRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormWorkflowRepository) GetWorkflow(ctx context.Context, userID uint) (model.Workflow, error) {
+	workflow, err := db.GetWorkflow(r.db.WithContext(ctx), userID)
+	return workflow, translateError(err)
+}
+
+func (r *gormWorkflowRepository) SaveWorkflow(ctx context.Context, userID uint, workflow model.Workflow) error {
+	return translateError(db.SaveWorkflow(r.db.WithContext(ctx), userID, workflow))
+}
+
+func (r *gormWorkflowRepository) DeleteWorkflow(ctx context.Context, userID uint) error {
+	return translateError(db.DeleteWorkflow(r.db.WithContext(ctx), userID))
+}
+
+type gormTagRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTagRepository) GetTagList(ctx context.Context, userID uint) ([]model.TagCount, error) {
+	tags, err := db.GetTagList(r.db.WithContext(ctx), userID)
+	return tags, translateError(err)
+}
+
+func (r *gormTagRepository) AddNewTag(ctx context.Context, userID uint, name string) (model.Tag, error) {
+	tag, err := db.AddNewTag(r.db.WithContext(ctx), userID, name)
+	return tag, translateError(err)
+}
+
+func (r *gormTagRepository) RenameTag(ctx context.Context, userID uint, id uint, name string) (model.Tag, error) {
+	tag, err := db.RenameTag(r.db.WithContext(ctx), userID, id, name)
+	return tag, translateError(err)
+}
+
+func (r *gormTagRepository) DeleteTag(ctx context.Context, userID uint, id uint) (model.Tag, error) {
+	tag, err := db.DeleteTag(r.db.WithContext(ctx), userID, id)
+	return tag, translateError(err)
+}
+
+type gormProjectRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormProjectRepository) GetProjectList(ctx context.Context, userID uint, archived bool) ([]model.Project, error) {
+	projects, err := db.GetProjectList(r.db.WithContext(ctx), userID, archived)
+	return projects, translateError(err)
+}
+
+func (r *gormProjectRepository) GetProjectByID(ctx context.Context, userID uint, id uint) (model.Project, error) {
+	project, err := db.GetProjectByID(r.db.WithContext(ctx), userID, id)
+	return project, translateError(err)
+}
+
+func (r *gormProjectRepository) AddNewProject(ctx context.Context, userID uint, payload model.ProjectPayload) (model.Project, error) {
+	project, err := db.AddNewProject(r.db.WithContext(ctx), userID, payload)
+	return project, translateError(err)
+}
+
+func (r *gormProjectRepository) UpdateProject(ctx context.Context, userID uint, id uint, update model.ProjectUpdate) (model.Project, error) {
+	project, err := db.UpdateProject(r.db.WithContext(ctx), userID, id, update)
+	return project, translateError(err)
+}
+
+func (r *gormProjectRepository) DeleteProject(ctx context.Context, userID uint, id uint, deleteTodos bool) (model.Project, error) {
+	project, err := db.DeleteProject(r.db.WithContext(ctx), userID, id, deleteTodos)
+	return project, translateError(err)
+}
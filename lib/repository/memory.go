@@ -0,0 +1,1263 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/lib/util"
+	"gorm.io/gorm"
+)
+
+// memoryStore はインメモリのRepositoryが共有するデータ
+// 全てのデータへのアクセスはmuで排他制御する
+type memoryStore struct {
+	mu sync.RWMutex
+
+	todos      map[uint]model.Todo
+	nextTodoID uint
+	// trash はゴミ箱に移動したTodoを保持する。タグ及び依存関係は元に戻す場合のために残す
+	trash map[uint]model.Todo
+
+	users      map[uint]model.User
+	nextUserID uint
+
+	revokedTokens map[string]model.RevokedToken
+
+	apiKeys      map[uint]model.APIKey
+	nextAPIKeyID uint
+
+	workflows map[uint]model.Workflow
+
+	tags      map[uint]model.Tag
+	nextTagID uint
+	// todoTags はTodoのID毎に設定されたタグのIDを保持する
+	todoTags map[uint][]uint
+
+	projects      map[uint]model.Project
+	nextProjectID uint
+
+	// dependencies はTodoのID毎に終了を待つTodoのIDを保持する
+	dependencies map[uint][]uint
+}
+
+// NewMemory はプロセス内のメモリにデータを保持するRepositoryの一式を生成する
+// 開発及びテスト用で、プロセスを終了するとデータは失われる
+func NewMemory() Repositories {
+	store := &memoryStore{
+		todos:         map[uint]model.Todo{},
+		nextTodoID:    1,
+		trash:         map[uint]model.Todo{},
+		users:         map[uint]model.User{},
+		nextUserID:    1,
+		revokedTokens: map[string]model.RevokedToken{},
+		apiKeys:       map[uint]model.APIKey{},
+		nextAPIKeyID:  1,
+		workflows:     map[uint]model.Workflow{},
+		tags:          map[uint]model.Tag{},
+		nextTagID:     1,
+		todoTags:      map[uint][]uint{},
+		projects:      map[uint]model.Project{},
+		nextProjectID: 1,
+		dependencies:  map[uint][]uint{},
+	}
+	return Repositories{
+		Todos:     &memoryTodoRepository{store: store},
+		Users:     &memoryUserRepository{store: store},
+		Tokens:    &memoryTokenRepository{store: store},
+		APIKeys:   &memoryAPIKeyRepository{store: store},
+		Workflows: &memoryWorkflowRepository{store: store},
+		Tags:      &memoryTagRepository{store: store},
+		Projects:  &memoryProjectRepository{store: store},
+	}
+}
+
+// findTodo は指定ユーザーのTodoを取得する (呼び出し側でロックを取得すること)
+func (s *memoryStore) findTodo(userID uint, id uint) (model.Todo, error) {
+	todo, ok := s.todos[id]
+	if !ok || todo.UserID != userID {
+		return model.Todo{}, ErrNotFound
+	}
+	return s.withDetails(todo), nil
+}
+
+// withDetails はTodoに設定されたタグ名を名前順で設定し、子の完了率及び依存関係を設定する (呼び出し側でロックを取得すること)
+// ゴミ箱のTodoとの依存関係は含めない
+func (s *memoryStore) withDetails(todo model.Todo) model.Todo {
+	todo.Tags = []string{}
+	for _, tagID := range s.todoTags[todo.ID] {
+		todo.Tags = append(todo.Tags, s.tags[tagID].Name)
+	}
+	sort.Strings(todo.Tags)
+	statuses := []string{}
+	for _, child := range s.todos {
+		if child.ParentID != nil && *child.ParentID == todo.ID {
+			statuses = append(statuses, child.Status)
+		}
+	}
+	todo.Progress = s.workflow(todo.UserID).Progress(statuses)
+	todo.BlockedBy = []uint{}
+	for _, blockerID := range s.dependencies[todo.ID] {
+		if _, ok := s.todos[blockerID]; ok {
+			todo.BlockedBy = append(todo.BlockedBy, blockerID)
+		}
+	}
+	sortIDs(todo.BlockedBy)
+	todo.Blocking = []uint{}
+	for id, blockerIDs := range s.dependencies {
+		if _, ok := s.todos[id]; !ok {
+			continue
+		}
+		for _, blockerID := range blockerIDs {
+			if blockerID == todo.ID {
+				todo.Blocking = append(todo.Blocking, id)
+			}
+		}
+	}
+	sortIDs(todo.Blocking)
+	return todo
+}
+
+func sortIDs(ids []uint) []uint {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// todoParent はTodoに設定する親を確認する (呼び出し側でロックを取得すること)
+// idは新規作成の場合は0とする。0の親はTodoが親を持たないことを表すため、nilを返却する
+func (s *memoryStore) todoParent(userID uint, id uint, parentID uint) (*uint, error) {
+	if parentID == 0 {
+		return nil, nil
+	}
+	parent, ok := s.todos[parentID]
+	if !ok || parent.UserID != userID {
+		return nil, ErrInvalidParent
+	}
+	parents := map[uint]uint{}
+	for _, todo := range s.todos {
+		if todo.UserID == userID && todo.ParentID != nil {
+			parents[todo.ID] = *todo.ParentID
+		}
+	}
+	if id != 0 && model.HasAncestor(parents, parentID, id) {
+		return nil, ErrParentCycle
+	}
+	return &parent.ID, nil
+}
+
+// completeDescendants はTodoを完了に変更する場合に、未完了の子孫の変更内容を返却する (呼び出し側でロックを取得すること)
+// 完了にするTodoに終了していないBlockerがある場合は model.OpenBlockersError を返却する
+func (s *memoryStore) completeDescendants(workflow model.Workflow, target model.Todo, from string, complete bool) ([]model.StatusChange, error) {
+	if !workflow.IsCompleted(target.Status) || target.Status == from {
+		return nil, nil
+	}
+	descendants := []model.Todo{}
+	parentIDs := map[uint]bool{target.ID: true}
+	for len(parentIDs) > 0 {
+		children := map[uint]bool{}
+		for _, todo := range s.todos {
+			if todo.ParentID != nil && parentIDs[*todo.ParentID] {
+				descendants = append(descendants, todo)
+				children[todo.ID] = true
+			}
+		}
+		parentIDs = children
+	}
+	sort.Slice(descendants, func(i, j int) bool {
+		return descendants[i].ID < descendants[j].ID
+	})
+	changes, err := workflow.CompleteDescendants(descendants, target.Status, complete, target.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	completing := []uint{target.ID}
+	for _, change := range changes {
+		completing = append(completing, change.Todo.ID)
+	}
+	blockers := []model.Todo{}
+	for _, id := range completing {
+		for _, blockerID := range s.dependencies[id] {
+			if blocker, ok := s.todos[blockerID]; ok {
+				blockers = append(blockers, blocker)
+			}
+		}
+	}
+	if open := workflow.OpenBlockers(blockers, completing); len(open) > 0 {
+		return nil, &model.OpenBlockersError{IDs: open}
+	}
+	return changes, nil
+}
+
+// createNextOccurrence は繰り返しのTodoを完了に変更した場合に、次のTodoを作成して繰り返しのルールを引き継ぐ
+// (呼び出し側でロックを取得すること)
+func (s *memoryStore) createNextOccurrence(workflow model.Workflow, target *model.Todo, from string) {
+	if !workflow.IsCompleted(target.Status) || target.Status == from || target.Recurrence == "" {
+		return
+	}
+	if next, ok := workflow.NextOccurrence(*target, target.UpdatedAt); ok {
+		next.ID = s.nextTodoID
+		s.todos[next.ID] = next
+		s.setTodoTags(target.UserID, next.ID, next.Tags)
+		s.nextTodoID++
+	}
+	target.Recurrence = ""
+}
+
+// applyStatusChanges は completeDescendants で完了にした子孫を保存し、繰り返しの子孫は次のTodoを作成する
+// (呼び出し側でロックを取得すること)
+func (s *memoryStore) applyStatusChanges(workflow model.Workflow, changes []model.StatusChange) {
+	for _, change := range changes {
+		descendant := s.withDetails(change.Todo)
+		s.createNextOccurrence(workflow, &descendant, change.From)
+		s.todos[descendant.ID] = descendant
+	}
+}
+
+// trashTodo はTodoをゴミ箱に移動し、子を親を持たないTodoにする (呼び出し側でロックを取得すること)
+func (s *memoryStore) trashTodo(id uint, now time.Time) model.Todo {
+	s.detachChildren(id)
+	todo := s.todos[id]
+	todo.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	delete(s.todos, id)
+	s.trash[id] = todo
+	return todo
+}
+
+// purgeTodo はゴミ箱を含めてTodoを完全に削除し、子を親を持たないTodoにして依存関係を外す (呼び出し側でロックを取得すること)
+func (s *memoryStore) purgeTodo(id uint) {
+	s.detachChildren(id)
+	delete(s.todos, id)
+	delete(s.trash, id)
+	delete(s.todoTags, id)
+	delete(s.dependencies, id)
+	for todoID, blockerIDs := range s.dependencies {
+		kept := []uint{}
+		for _, blockerID := range blockerIDs {
+			if blockerID != id {
+				kept = append(kept, blockerID)
+			}
+		}
+		s.dependencies[todoID] = kept
+	}
+}
+
+// detachChildren は指定のTodoの子を、ゴミ箱の子も含めて親を持たないTodoにする (呼び出し側でロックを取得すること)
+func (s *memoryStore) detachChildren(parentID uint) {
+	for _, todos := range []map[uint]model.Todo{s.todos, s.trash} {
+		for id, todo := range todos {
+			if todo.ParentID != nil && *todo.ParentID == parentID {
+				todo.ParentID = nil
+				todos[id] = todo
+			}
+		}
+	}
+}
+
+// setTodoTags はTodoのタグを置き換え、存在しないタグは作成する (呼び出し側でロックを取得すること)
+func (s *memoryStore) setTodoTags(userID uint, todoID uint, names []string) {
+	tagIDs := []uint{}
+	for _, name := range names {
+		tag, ok := s.findTagByName(userID, name)
+		if !ok {
+			tag = model.Tag{ID: s.nextTagID, UserID: userID, Name: name, CreatedAt: time.Now()}
+			s.tags[tag.ID] = tag
+			s.nextTagID++
+		}
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	s.todoTags[todoID] = tagIDs
+}
+
+// findTagByName は名前から指定ユーザーのタグを取得する (呼び出し側でロックを取得すること)
+func (s *memoryStore) findTagByName(userID uint, name string) (model.Tag, bool) {
+	for _, tag := range s.tags {
+		if tag.UserID == userID && tag.Name == name {
+			return tag, true
+		}
+	}
+	return model.Tag{}, false
+}
+
+// deleteTag はタグを削除し、全てのTodoから外す (呼び出し側でロックを取得すること)
+func (s *memoryStore) deleteTag(id uint) {
+	for todoID, tagIDs := range s.todoTags {
+		kept := []uint{}
+		for _, tagID := range tagIDs {
+			if tagID != id {
+				kept = append(kept, tagID)
+			}
+		}
+		s.todoTags[todoID] = kept
+	}
+	delete(s.tags, id)
+}
+
+// todoProject はTodoに設定するプロジェクトを確認する (呼び出し側でロックを取得すること)
+// 0はプロジェクトに属さないことを表すため、nilを返却する
+func (s *memoryStore) todoProject(userID uint, projectID uint) (*uint, error) {
+	if projectID == 0 {
+		return nil, nil
+	}
+	project, ok := s.projects[projectID]
+	if !ok || project.UserID != userID || project.Archived {
+		return nil, ErrInvalidProject
+	}
+	return &project.ID, nil
+}
+
+// findProjectByName は名前から指定ユーザーのプロジェクトを取得する (呼び出し側でロックを取得すること)
+func (s *memoryStore) findProjectByName(userID uint, name string) (model.Project, bool) {
+	for _, project := range s.projects {
+		if project.UserID == userID && project.Name == name {
+			return project, true
+		}
+	}
+	return model.Project{}, false
+}
+
+// workflow は指定ユーザーのワークフローを取得する (呼び出し側でロックを取得すること)
+func (s *memoryStore) workflow(userID uint) model.Workflow {
+	if workflow, ok := s.workflows[userID]; ok {
+		return workflow
+	}
+	return model.DefaultWorkflow()
+}
+
+// findUserByName は名前からユーザーを取得する (呼び出し側でロックを取得すること)
+func (s *memoryStore) findUserByName(name string) (model.User, bool) {
+	for _, user := range s.users {
+		if user.Name == name {
+			return user, true
+		}
+	}
+	return model.User{}, false
+}
+
+// addNewTodo は新規のTodoを作成する (呼び出し側でロックを取得すること)
+func (s *memoryStore) addNewTodo(userID uint, payload model.Payload) (model.Todo, error) {
+	var projectID *uint
+	if payload.ProjectID != nil {
+		var err error
+		if projectID, err = s.todoProject(userID, *payload.ProjectID); err != nil {
+			return model.Todo{}, err
+		}
+	}
+	var parentID *uint
+	if payload.ParentID != nil {
+		var err error
+		if parentID, err = s.todoParent(userID, 0, *payload.ParentID); err != nil {
+			return model.Todo{}, err
+		}
+	}
+	now := time.Now()
+	newTodo := model.Todo{
+		ID:        s.nextTodoID,
+		UserID:    userID,
+		ProjectID: projectID,
+		ParentID:  parentID,
+		Title:     payload.Title,
+		Details:   payload.Details,
+		Priority:  payload.Priority,
+		StartAt:   payload.StartAt,
+		DueAt:     payload.DueAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if payload.Recurrence != nil {
+		newTodo.Recurrence = *payload.Recurrence
+		newTodo.Timezone = payload.Timezone
+	}
+	if payload.Archived != nil {
+		model.SetArchived(&newTodo, *payload.Archived, now)
+	}
+	if err := s.workflow(userID).Apply(&newTodo, payload.Status, now); err != nil {
+		return model.Todo{}, err
+	}
+	tags, err := model.NormalizeTagNames(payload.Tags)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	s.todos[newTodo.ID] = newTodo
+	s.setTodoTags(userID, newTodo.ID, tags)
+	s.nextTodoID++
+	return s.withDetails(newTodo), nil
+}
+
+// updateItem はTodoを更新する (呼び出し側でロックを取得すること)
+func (s *memoryStore) updateItem(userID uint, id uint, payload model.Payload) (model.Todo, error) {
+	target, err := s.findTodo(userID, id)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	target.Title = payload.Title
+	target.Details = payload.Details
+	target.Priority = payload.Priority
+	target.StartAt = payload.StartAt
+	target.DueAt = payload.DueAt
+	if payload.ProjectID != nil {
+		if target.ProjectID, err = s.todoProject(userID, *payload.ProjectID); err != nil {
+			return model.Todo{}, err
+		}
+	}
+	if payload.ParentID != nil {
+		if target.ParentID, err = s.todoParent(userID, id, *payload.ParentID); err != nil {
+			return model.Todo{}, err
+		}
+	}
+	if payload.Recurrence != nil {
+		target.Recurrence = *payload.Recurrence
+		target.Timezone = payload.Timezone
+	}
+	from := target.Status
+	target.UpdatedAt = time.Now()
+	if payload.Archived != nil {
+		model.SetArchived(&target, *payload.Archived, target.UpdatedAt)
+	}
+	workflow := s.workflow(userID)
+	if err := workflow.Apply(&target, payload.Status, target.UpdatedAt); err != nil {
+		return model.Todo{}, err
+	}
+	changes, err := s.completeDescendants(workflow, target, from, payload.CompleteChildren)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	var tags []string
+	if payload.Tags != nil {
+		if tags, err = model.NormalizeTagNames(payload.Tags); err != nil {
+			return model.Todo{}, err
+		}
+		s.setTodoTags(userID, id, tags)
+		target.Tags = tags
+	}
+	s.applyStatusChanges(workflow, changes)
+	s.createNextOccurrence(workflow, &target, from)
+	s.todos[id] = target
+	return s.withDetails(target), nil
+}
+
+// updateItemStatus はTodoのStatusを変更する (呼び出し側でロックを取得すること)
+func (s *memoryStore) updateItemStatus(userID uint, id uint, status model.Status) (model.Todo, error) {
+	target, err := s.findTodo(userID, id)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	from := target.Status
+	target.UpdatedAt = time.Now()
+	workflow := s.workflow(userID)
+	if err := workflow.Apply(&target, status.Status, target.UpdatedAt); err != nil {
+		return model.Todo{}, err
+	}
+	changes, err := s.completeDescendants(workflow, target, from, status.CompleteChildren)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	s.applyStatusChanges(workflow, changes)
+	s.createNextOccurrence(workflow, &target, from)
+	s.todos[id] = target
+	return s.withDetails(target), nil
+}
+
+// deleteItem はTodoをゴミ箱に移動する (呼び出し側でロックを取得すること)
+func (s *memoryStore) deleteItem(userID uint, id uint) (model.Todo, error) {
+	if _, err := s.findTodo(userID, id); err != nil {
+		return model.Todo{}, err
+	}
+	return s.withDetails(s.trashTodo(id, time.Now())), nil
+}
+
+// todoSnapshot は一括処理を取り消すために保存する、Todoの操作で変更されるデータの複製
+type todoSnapshot struct {
+	todos        map[uint]model.Todo
+	nextTodoID   uint
+	trash        map[uint]model.Todo
+	tags         map[uint]model.Tag
+	nextTagID    uint
+	todoTags     map[uint][]uint
+	dependencies map[uint][]uint
+}
+
+// snapshotTodos はTodoの操作で変更されるデータを複製する (呼び出し側でロックを取得すること)
+func (s *memoryStore) snapshotTodos() todoSnapshot {
+	snapshot := todoSnapshot{
+		todos:        map[uint]model.Todo{},
+		nextTodoID:   s.nextTodoID,
+		trash:        map[uint]model.Todo{},
+		tags:         map[uint]model.Tag{},
+		nextTagID:    s.nextTagID,
+		todoTags:     map[uint][]uint{},
+		dependencies: map[uint][]uint{},
+	}
+	for id, todo := range s.todos {
+		snapshot.todos[id] = todo
+	}
+	for id, todo := range s.trash {
+		snapshot.trash[id] = todo
+	}
+	for id, tag := range s.tags {
+		snapshot.tags[id] = tag
+	}
+	for id, tagIDs := range s.todoTags {
+		snapshot.todoTags[id] = append([]uint{}, tagIDs...)
+	}
+	for id, blockerIDs := range s.dependencies {
+		snapshot.dependencies[id] = append([]uint{}, blockerIDs...)
+	}
+	return snapshot
+}
+
+// restoreTodos は snapshotTodos で複製したデータに戻す (呼び出し側でロックを取得すること)
+func (s *memoryStore) restoreTodos(snapshot todoSnapshot) {
+	s.todos = snapshot.todos
+	s.nextTodoID = snapshot.nextTodoID
+	s.trash = snapshot.trash
+	s.tags = snapshot.tags
+	s.nextTagID = snapshot.nextTagID
+	s.todoTags = snapshot.todoTags
+	s.dependencies = snapshot.dependencies
+}
+
+type memoryTodoRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTodoRepository) GetTodoList(ctx context.Context, userID uint, filter model.TodoFilter, page model.TodoPage) (model.TodoList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	order := page.Sort
+	if len(order) == 0 {
+		order = model.TodoSort{{Field: "id"}}
+	}
+	todoList := model.TodoList{}
+	for _, todo := range r.store.todos {
+		if todo.UserID != userID {
+			continue
+		}
+		todo = r.store.withDetails(todo)
+		if !filter.Match(todo) {
+			continue
+		}
+		if page.After != nil && order.Compare(*page.After, todo) >= 0 {
+			continue
+		}
+		todoList = append(todoList, todo)
+	}
+	sort.Slice(todoList, func(i, j int) bool {
+		return order.Compare(todoList[i], todoList[j]) < 0
+	})
+	if page.Limit > 0 && len(todoList) > page.Limit {
+		todoList = todoList[:page.Limit]
+	}
+	return todoList, nil
+}
+
+func (r *memoryTodoRepository) GetScheduledTodoList(ctx context.Context, userID uint, filter model.TodoFilter, page model.SchedulePage) (model.TodoList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todoList := model.TodoList{}
+	for _, todo := range r.store.todos {
+		if todo.UserID != userID || todo.DueAt == nil {
+			continue
+		}
+		todo = r.store.withDetails(todo)
+		if !filter.Match(todo) {
+			continue
+		}
+		if page.After != nil && model.CompareDue(*page.After, todo) >= 0 {
+			continue
+		}
+		todoList = append(todoList, todo)
+	}
+	sort.Slice(todoList, func(i, j int) bool {
+		return model.CompareDue(todoList[i], todoList[j]) < 0
+	})
+	if page.Limit > 0 && len(todoList) > page.Limit {
+		todoList = todoList[:page.Limit]
+	}
+	return todoList, nil
+}
+
+func (r *memoryTodoRepository) SearchTodo(ctx context.Context, userID uint, query string, limit int) ([]model.TodoSearchResult, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todoList := model.TodoList{}
+	for _, todo := range r.store.todos {
+		if todo.UserID == userID {
+			todoList = append(todoList, r.store.withDetails(todo))
+		}
+	}
+	return model.RankTodoSearch(todoList, query, limit), nil
+}
+
+func (r *memoryTodoRepository) GetTodoItemByID(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.findTodo(userID, id)
+}
+
+func (r *memoryTodoRepository) AddNewTodo(ctx context.Context, userID uint, payload model.Payload) (model.Todo, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.addNewTodo(userID, payload)
+}
+
+func (r *memoryTodoRepository) UpdateItem(ctx context.Context, userID uint, id uint, payload model.Payload) (model.Todo, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.updateItem(userID, id, payload)
+}
+
+func (r *memoryTodoRepository) UpdateItemStatus(ctx context.Context, userID uint, id uint, status model.Status) (model.Todo, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.updateItemStatus(userID, id, status)
+}
+
+func (r *memoryTodoRepository) DeleteItem(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.deleteItem(userID, id)
+}
+
+func (r *memoryTodoRepository) AddDependency(ctx context.Context, userID uint, id uint, blockerID uint) (model.Todo, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, err := r.store.findTodo(userID, id); err != nil {
+		return model.Todo{}, err
+	}
+	if _, err := r.store.findTodo(userID, blockerID); err != nil {
+		return model.Todo{}, ErrInvalidBlocker
+	}
+	for _, existing := range r.store.dependencies[id] {
+		if existing == blockerID {
+			return r.store.findTodo(userID, id)
+		}
+	}
+	if model.DependsOn(r.store.dependencies, blockerID, id) {
+		return model.Todo{}, ErrDependencyCycle
+	}
+	r.store.dependencies[id] = append(r.store.dependencies[id], blockerID)
+	return r.store.findTodo(userID, id)
+}
+
+func (r *memoryTodoRepository) RemoveDependency(ctx context.Context, userID uint, id uint, blockerID uint) (model.Todo, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, err := r.store.findTodo(userID, id); err != nil {
+		return model.Todo{}, err
+	}
+	kept := []uint{}
+	for _, existing := range r.store.dependencies[id] {
+		if existing != blockerID {
+			kept = append(kept, existing)
+		}
+	}
+	if len(kept) == len(r.store.dependencies[id]) {
+		return model.Todo{}, ErrNotFound
+	}
+	r.store.dependencies[id] = kept
+	return r.store.findTodo(userID, id)
+}
+
+func (r *memoryTodoRepository) StopRecurrence(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, err := r.store.findTodo(userID, id)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	target.Recurrence = ""
+	target.UpdatedAt = time.Now()
+	r.store.todos[id] = target
+	return r.store.withDetails(target), nil
+}
+
+func (r *memoryTodoRepository) GetTrashList(ctx context.Context, userID uint) (model.TodoList, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todoList := model.TodoList{}
+	for _, todo := range r.store.trash {
+		if todo.UserID == userID {
+			todoList = append(todoList, r.store.withDetails(todo))
+		}
+	}
+	sort.Slice(todoList, func(i, j int) bool {
+		a, b := todoList[i], todoList[j]
+		if !a.DeletedAt.Time.Equal(b.DeletedAt.Time) {
+			return a.DeletedAt.Time.After(b.DeletedAt.Time)
+		}
+		return a.ID > b.ID
+	})
+	return todoList, nil
+}
+
+func (r *memoryTodoRepository) RestoreItem(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.trash[id]
+	if !ok || target.UserID != userID {
+		return model.Todo{}, ErrNotFound
+	}
+	target.DeletedAt = gorm.DeletedAt{}
+	target.UpdatedAt = time.Now()
+	delete(r.store.trash, id)
+	r.store.todos[id] = target
+	return r.store.withDetails(target), nil
+}
+
+func (r *memoryTodoRepository) PurgeItem(ctx context.Context, userID uint, id uint) (model.Todo, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.trash[id]
+	if !ok || target.UserID != userID {
+		return model.Todo{}, ErrNotFound
+	}
+	target = r.store.withDetails(target)
+	r.store.purgeTodo(id)
+	return target, nil
+}
+
+func (r *memoryTodoRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for id, todo := range r.store.trash {
+		if todo.DeletedAt.Time.Before(before) {
+			r.store.purgeTodo(id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryTodoRepository) ArchiveCompleted(ctx context.Context, userID uint, before time.Time) ([]uint, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	workflow := r.store.workflow(userID)
+	now := time.Now()
+	ids := []uint{}
+	for id, todo := range r.store.todos {
+		if todo.UserID != userID || !workflow.CanArchiveCompleted(todo, before) {
+			continue
+		}
+		todo.ArchivedAt = &now
+		todo.UpdatedAt = now
+		r.store.todos[id] = todo
+		ids = append(ids, id)
+	}
+	return sortIDs(ids), nil
+}
+
+func (r *memoryTodoRepository) Bulk(ctx context.Context, userID uint, ops []model.BulkOperation, atomic bool) ([]model.BulkResult, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Note: 各操作は検証に失敗した場合にデータを変更しないため、取り消しは全体のみ行う
+	snapshot := r.store.snapshotTodos()
+	results := make([]model.BulkResult, len(ops))
+	for i, op := range ops {
+		var todo model.Todo
+		var err error
+		switch op.Op {
+		case model.BulkCreate:
+			todo, err = r.store.addNewTodo(userID, op.Payload)
+		case model.BulkUpdate:
+			todo, err = r.store.updateItem(userID, op.ID, op.Payload)
+		case model.BulkStatus:
+			todo, err = r.store.updateItemStatus(userID, op.ID, op.Status)
+		case model.BulkDelete:
+			todo, err = r.store.deleteItem(userID, op.ID)
+		default:
+			err = fmt.Errorf("repository: unknown bulk operation %q", op.Op)
+		}
+		results[i] = model.BulkResult{Todo: todo, Err: err}
+		if err != nil && atomic {
+			r.store.restoreTodos(snapshot)
+			break
+		}
+	}
+	return results, nil
+}
+
+type memoryUserRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryUserRepository) CheckUserAuth(ctx context.Context, name, password string) (model.User, bool) {
+	r.store.mu.RLock()
+	user, ok := r.store.findUserByName(name)
+	r.store.mu.RUnlock()
+
+	if !ok {
+		util.CompareDummyPassword(password)
+		return model.User{}, false
+	}
+	if !util.ComparePassword(user.Password, password) {
+		return model.User{}, false
+	}
+	return user, true
+}
+
+func (r *memoryUserRepository) GetUserByID(ctx context.Context, id uint) (model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return model.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUserRepository) GetUserByName(ctx context.Context, name string) (model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.findUserByName(name)
+	if !ok {
+		return model.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUserRepository) AddNewUser(ctx context.Context, payload model.UserPayload) (model.User, error) {
+	hashed, err := util.HashPassword(payload.Password)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, taken := r.store.findUserByName(payload.Name); taken {
+		return model.User{}, ErrUserNameTaken
+	}
+	now := time.Now()
+	newUser := model.User{
+		ID:        r.store.nextUserID,
+		Name:      payload.Name,
+		Password:  hashed,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.store.users[newUser.ID] = newUser
+	r.store.nextUserID++
+	return newUser, nil
+}
+
+func (r *memoryUserRepository) UpdateUser(ctx context.Context, id uint, payload model.UserPayload) (model.User, error) {
+	var hashed string
+	if payload.Password != "" {
+		var err error
+		if hashed, err = util.HashPassword(payload.Password); err != nil {
+			return model.User{}, err
+		}
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.users[id]
+	if !ok {
+		return model.User{}, ErrNotFound
+	}
+	if payload.Name != "" && payload.Name != target.Name {
+		if _, taken := r.store.findUserByName(payload.Name); taken {
+			return model.User{}, ErrUserNameTaken
+		}
+		target.Name = payload.Name
+	}
+	now := time.Now()
+	if hashed != "" {
+		target.Password = hashed
+		target.PasswordChangedAt = &now
+	}
+	target.UpdatedAt = now
+	r.store.users[id] = target
+	return target, nil
+}
+
+func (r *memoryUserRepository) DeleteUser(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[id]; !ok {
+		return ErrNotFound
+	}
+	for _, todos := range []map[uint]model.Todo{r.store.todos, r.store.trash} {
+		for todoID, todo := range todos {
+			if todo.UserID == id {
+				r.store.purgeTodo(todoID)
+			}
+		}
+	}
+	for keyID, apiKey := range r.store.apiKeys {
+		if apiKey.UserID == id {
+			delete(r.store.apiKeys, keyID)
+		}
+	}
+	for tagID, tag := range r.store.tags {
+		if tag.UserID == id {
+			r.store.deleteTag(tagID)
+		}
+	}
+	for projectID, project := range r.store.projects {
+		if project.UserID == id {
+			delete(r.store.projects, projectID)
+		}
+	}
+	delete(r.store.workflows, id)
+	delete(r.store.users, id)
+	return nil
+}
+
+type memoryTokenRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTokenRepository) RevokeToken(ctx context.Context, jti string, userID uint, expiresAt time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.revokedTokens[jti]; ok {
+		return false, nil
+	}
+	r.store.revokedTokens[jti] = model.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	return true, nil
+}
+
+func (r *memoryTokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.revokedTokens[jti]
+	return ok, nil
+}
+
+func (r *memoryTokenRepository) PurgeRevokedTokens(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for jti, token := range r.store.revokedTokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.store.revokedTokens, jti)
+			count++
+		}
+	}
+	return count, nil
+}
+
+type memoryAPIKeyRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryAPIKeyRepository) GetAPIKeyList(ctx context.Context, userID uint) ([]model.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := []model.APIKey{}
+	for _, apiKey := range r.store.apiKeys {
+		if apiKey.UserID == userID {
+			keys = append(keys, apiKey)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+func (r *memoryAPIKeyRepository) AddNewAPIKey(ctx context.Context, userID uint, payload model.APIKeyPayload) (model.APIKey, string, error) {
+	key, prefix, hash, err := util.GenerateAPIKey()
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	newKey := model.APIKey{
+		ID:        r.store.nextAPIKeyID,
+		UserID:    userID,
+		Name:      payload.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scope:     payload.Scope,
+		ExpiresAt: payload.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	r.store.apiKeys[newKey.ID] = newKey
+	r.store.nextAPIKeyID++
+	return newKey, key, nil
+}
+
+func (r *memoryAPIKeyRepository) DeleteAPIKey(ctx context.Context, userID uint, id uint) (model.APIKey, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.apiKeys[id]
+	if !ok || target.UserID != userID {
+		return model.APIKey{}, ErrNotFound
+	}
+	delete(r.store.apiKeys, id)
+	return target, nil
+}
+
+func (r *memoryAPIKeyRepository) CheckAPIKey(ctx context.Context, key string) (model.User, model.APIKey, bool) {
+	prefix, err := util.SplitAPIKeyPrefix(key)
+	if err != nil {
+		return model.User{}, model.APIKey{}, false
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, apiKey := range r.store.apiKeys {
+		if apiKey.Prefix != prefix {
+			continue
+		}
+		if !util.CompareAPIKey(apiKey.KeyHash, key) {
+			return model.User{}, model.APIKey{}, false
+		}
+		now := time.Now()
+		if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+			return model.User{}, model.APIKey{}, false
+		}
+		user, ok := r.store.users[apiKey.UserID]
+		if !ok {
+			return model.User{}, model.APIKey{}, false
+		}
+		apiKey.LastUsedAt = &now
+		r.store.apiKeys[id] = apiKey
+		return user, apiKey, true
+	}
+	return model.User{}, model.APIKey{}, false
+}
+
+type memoryWorkflowRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryWorkflowRepository) GetWorkflow(ctx context.Context, userID uint) (model.Workflow, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.workflow(userID), nil
+}
+
+func (r *memoryWorkflowRepository) SaveWorkflow(ctx context.Context, userID uint, workflow model.Workflow) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.workflows[userID] = workflow
+	return nil
+}
+
+func (r *memoryWorkflowRepository) DeleteWorkflow(ctx context.Context, userID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.workflows, userID)
+	return nil
+}
+
+type memoryTagRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTagRepository) GetTagList(ctx context.Context, userID uint) ([]model.TagCount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := map[uint]int{}
+	for todoID, tagIDs := range r.store.todoTags {
+		if _, ok := r.store.todos[todoID]; !ok {
+			continue
+		}
+		for _, tagID := range tagIDs {
+			counts[tagID]++
+		}
+	}
+	tags := []model.TagCount{}
+	for _, tag := range r.store.tags {
+		if tag.UserID == userID {
+			tags = append(tags, model.TagCount{Tag: tag, Count: counts[tag.ID]})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func (r *memoryTagRepository) AddNewTag(ctx context.Context, userID uint, name string) (model.Tag, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.findTagByName(userID, name); ok {
+		return model.Tag{}, ErrTagNameTaken
+	}
+	newTag := model.Tag{ID: r.store.nextTagID, UserID: userID, Name: name, CreatedAt: time.Now()}
+	r.store.tags[newTag.ID] = newTag
+	r.store.nextTagID++
+	return newTag, nil
+}
+
+func (r *memoryTagRepository) RenameTag(ctx context.Context, userID uint, id uint, name string) (model.Tag, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.tags[id]
+	if !ok || target.UserID != userID {
+		return model.Tag{}, ErrNotFound
+	}
+	if other, ok := r.store.findTagByName(userID, name); ok && other.ID != id {
+		return model.Tag{}, ErrTagNameTaken
+	}
+	target.Name = name
+	r.store.tags[id] = target
+	return target, nil
+}
+
+func (r *memoryTagRepository) DeleteTag(ctx context.Context, userID uint, id uint) (model.Tag, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.tags[id]
+	if !ok || target.UserID != userID {
+		return model.Tag{}, ErrNotFound
+	}
+	r.store.deleteTag(id)
+	return target, nil
+}
+
+type memoryProjectRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryProjectRepository) GetProjectList(ctx context.Context, userID uint, archived bool) ([]model.Project, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	projects := []model.Project{}
+	for _, project := range r.store.projects {
+		if project.UserID == userID && project.Archived == archived {
+			projects = append(projects, project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+	return projects, nil
+}
+
+func (r *memoryProjectRepository) GetProjectByID(ctx context.Context, userID uint, id uint) (model.Project, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	project, ok := r.store.projects[id]
+	if !ok || project.UserID != userID {
+		return model.Project{}, ErrNotFound
+	}
+	return project, nil
+}
+
+func (r *memoryProjectRepository) AddNewProject(ctx context.Context, userID uint, payload model.ProjectPayload) (model.Project, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.findProjectByName(userID, payload.Name); ok {
+		return model.Project{}, ErrProjectNameTaken
+	}
+	now := time.Now()
+	newProject := model.Project{
+		ID:        r.store.nextProjectID,
+		UserID:    userID,
+		Name:      payload.Name,
+		Color:     payload.Color,
+		Archived:  payload.Archived,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.store.projects[newProject.ID] = newProject
+	r.store.nextProjectID++
+	return newProject, nil
+}
+
+func (r *memoryProjectRepository) UpdateProject(ctx context.Context, userID uint, id uint, update model.ProjectUpdate) (model.Project, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.projects[id]
+	if !ok || target.UserID != userID {
+		return model.Project{}, ErrNotFound
+	}
+	if update.Name != nil {
+		if other, ok := r.store.findProjectByName(userID, *update.Name); ok && other.ID != id {
+			return model.Project{}, ErrProjectNameTaken
+		}
+		target.Name = *update.Name
+	}
+	if update.Color != nil {
+		target.Color = *update.Color
+	}
+	if update.Archived != nil {
+		target.Archived = *update.Archived
+	}
+	target.UpdatedAt = time.Now()
+	r.store.projects[id] = target
+	return target, nil
+}
+
+func (r *memoryProjectRepository) DeleteProject(ctx context.Context, userID uint, id uint, deleteTodos bool) (model.Project, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.projects[id]
+	if !ok || target.UserID != userID {
+		return model.Project{}, ErrNotFound
+	}
+	now := time.Now()
+	for todoID, todo := range r.store.todos {
+		if deleteTodos && todo.UserID == userID && todo.ProjectID != nil && *todo.ProjectID == id {
+			r.store.trashTodo(todoID, now)
+		}
+	}
+	// Note: ゴミ箱のItemも含めてプロジェクトから外す
+	for _, todos := range []map[uint]model.Todo{r.store.todos, r.store.trash} {
+		for todoID, todo := range todos {
+			if todo.UserID != userID || todo.ProjectID == nil || *todo.ProjectID != id {
+				continue
+			}
+			todo.ProjectID = nil
+			todo.UpdatedAt = now
+			todos[todoID] = todo
+		}
+	}
+	delete(r.store.projects, id)
+	return target, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/util"
+	"github.com/Z-me/practice-todo-api/migration"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound は対象のデータが存在しない場合のエラー
+	ErrNotFound = errors.New("record not found")
+	// ErrUserNameTaken は既に同じ名前のユーザーが存在する場合のエラー
+	ErrUserNameTaken = errors.New("user name is already taken")
+	// ErrConflict は更新中に他のリクエストで対象のデータが変更された場合のエラー
+	ErrConflict = errors.New("record was modified concurrently")
+	// ErrTagNameTaken は指定ユーザーに同じ名前のタグが既に存在する場合のエラー
+	ErrTagNameTaken = errors.New("tag name is already taken")
+	// ErrProjectNameTaken は指定ユーザーに同じ名前のプロジェクトが既に存在する場合のエラー
+	ErrProjectNameTaken = errors.New("project name is already taken")
+	// ErrInvalidProject はTodoに指定したプロジェクトが存在しないかアーカイブ済みの場合のエラー
+	ErrInvalidProject = errors.New("project does not exist or is archived")
+	// ErrInvalidParent はTodoに指定した親が存在しない場合のエラー
+	ErrInvalidParent = errors.New("parent todo does not exist")
+	// ErrParentCycle はTodoに指定した親が自身または自身の子孫の場合のエラー
+	ErrParentCycle = errors.New("parent todo would create a cycle")
+	// ErrInvalidBlocker はBlockerに指定したTodoが存在しない場合のエラー
+	ErrInvalidBlocker = errors.New("blocker todo does not exist")
+	// ErrDependencyCycle はBlockerに指定したTodoが自身または自身の終了を待つTodoの場合のエラー
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+)
+
+// TodoRepository はTodoの永続化を扱う
+// 全ての操作は指定ユーザーのTodoのみを対象とする
+// Statusの設定及び変更はユーザーのワークフローに従い、違反した場合は
+// model.StatusError または model.TransitionError を返却する
+// 存在しないかアーカイブ済みのプロジェクトを指定した場合は ErrInvalidProject を返却する
+// 親には存在するTodoのみ指定でき、親子関係が循環する場合は ErrParentCycle を返却する
+// 完了できない未完了の子孫を持つTodoを完了にする場合は model.OpenChildrenError を、
+// 終了していないBlockerを持つTodoを完了にする場合は model.OpenBlockersError を返却する
+// 繰り返しのTodoを完了にした場合は次のTodoを作成する
+// DeleteItem はTodoをゴミ箱に移動し、ゴミ箱のTodoは RestoreItem で元に戻すか、
+// PurgeItem 及び全てのユーザーを対象とする PurgeTrash で完全に削除する
+// ArchiveCompleted はbeforeより前に完了したTodoをまとめてアーカイブする
+// Bulk は複数の作成、更新、Statusの変更及び削除を1つのトランザクションで実行し、各操作のエラーは結果に含める
+// atomic がtrueの場合は1件でも失敗すると全ての操作を取り消す
+type TodoRepository interface {
+	GetTodoList(ctx context.Context, userID uint, filter model.TodoFilter, page model.TodoPage) (model.TodoList, error)
+	GetScheduledTodoList(ctx context.Context, userID uint, filter model.TodoFilter, page model.SchedulePage) (model.TodoList, error)
+	SearchTodo(ctx context.Context, userID uint, query string, limit int) ([]model.TodoSearchResult, error)
+	GetTodoItemByID(ctx context.Context, userID uint, id uint) (model.Todo, error)
+	AddNewTodo(ctx context.Context, userID uint, payload model.Payload) (model.Todo, error)
+	UpdateItem(ctx context.Context, userID uint, id uint, payload model.Payload) (model.Todo, error)
+	UpdateItemStatus(ctx context.Context, userID uint, id uint, status model.Status) (model.Todo, error)
+	DeleteItem(ctx context.Context, userID uint, id uint) (model.Todo, error)
+	AddDependency(ctx context.Context, userID uint, id uint, blockerID uint) (model.Todo, error)
+	RemoveDependency(ctx context.Context, userID uint, id uint, blockerID uint) (model.Todo, error)
+	StopRecurrence(ctx context.Context, userID uint, id uint) (model.Todo, error)
+	GetTrashList(ctx context.Context, userID uint) (model.TodoList, error)
+	RestoreItem(ctx context.Context, userID uint, id uint) (model.Todo, error)
+	PurgeItem(ctx context.Context, userID uint, id uint) (model.Todo, error)
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	ArchiveCompleted(ctx context.Context, userID uint, before time.Time) ([]uint, error)
+	Bulk(ctx context.Context, userID uint, ops []model.BulkOperation, atomic bool) ([]model.BulkResult, error)
+}
+
+// UserRepository はユーザーの永続化と認証を扱う
+type UserRepository interface {
+	CheckUserAuth(ctx context.Context, name, password string) (model.User, bool)
+	GetUserByID(ctx context.Context, id uint) (model.User, error)
+	GetUserByName(ctx context.Context, name string) (model.User, error)
+	AddNewUser(ctx context.Context, payload model.UserPayload) (model.User, error)
+	UpdateUser(ctx context.Context, id uint, payload model.UserPayload) (model.User, error)
+	DeleteUser(ctx context.Context, id uint) error
+}
+
+// TokenRepository は失効済みのリフレッシュトークンを扱う
+// RevokeToken は既に失効済みの場合はfalseを返却する
+type TokenRepository interface {
+	RevokeToken(ctx context.Context, jti string, userID uint, expiresAt time.Time) (bool, error)
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	PurgeRevokedTokens(ctx context.Context, before time.Time) (int64, error)
+}
+
+// APIKeyRepository はユーザーのAPIキーを扱う
+type APIKeyRepository interface {
+	GetAPIKeyList(ctx context.Context, userID uint) ([]model.APIKey, error)
+	AddNewAPIKey(ctx context.Context, userID uint, payload model.APIKeyPayload) (model.APIKey, string, error)
+	DeleteAPIKey(ctx context.Context, userID uint, id uint) (model.APIKey, error)
+	CheckAPIKey(ctx context.Context, key string) (model.User, model.APIKey, bool)
+}
+
+// WorkflowRepository はユーザー毎のStatusのワークフローを扱う
+type WorkflowRepository interface {
+	GetWorkflow(ctx context.Context, userID uint) (model.Workflow, error)
+	SaveWorkflow(ctx context.Context, userID uint, workflow model.Workflow) error
+	DeleteWorkflow(ctx context.Context, userID uint) error
+}
+
+// TagRepository はユーザー毎のタグを扱う
+// Todoへのタグの設定は TodoRepository の Payload.Tags で行う
+type TagRepository interface {
+	GetTagList(ctx context.Context, userID uint) ([]model.TagCount, error)
+	AddNewTag(ctx context.Context, userID uint, name string) (model.Tag, error)
+	RenameTag(ctx context.Context, userID uint, id uint, name string) (model.Tag, error)
+	DeleteTag(ctx context.Context, userID uint, id uint) (model.Tag, error)
+}
+
+// ProjectRepository はユーザー毎のプロジェクトを扱う
+// Todoのプロジェクトへの割り当ては TodoRepository の Payload.ProjectID で行う
+type ProjectRepository interface {
+	GetProjectList(ctx context.Context, userID uint, archived bool) ([]model.Project, error)
+	GetProjectByID(ctx context.Context, userID uint, id uint) (model.Project, error)
+	AddNewProject(ctx context.Context, userID uint, payload model.ProjectPayload) (model.Project, error)
+	UpdateProject(ctx context.Context, userID uint, id uint, update model.ProjectUpdate) (model.Project, error)
+	DeleteProject(ctx context.Context, userID uint, id uint, deleteTodos bool) (model.Project, error)
+}
+
+// Repositories はハンドラー及びmiddlewareに注入するRepositoryの一式
+type Repositories struct {
+	Todos     TodoRepository
+	Users     UserRepository
+	Tokens    TokenRepository
+	APIKeys   APIKeyRepository
+	Workflows WorkflowRepository
+	Tags      TagRepository
+	Projects  ProjectRepository
+
+	close func() error
+}
+
+// Close はRepositoryが保持する接続を解放する
+func (r Repositories) Close() error {
+	if r.close == nil {
+		return nil
+	}
+	return r.close()
+}
+
+// Open は設定のDBドライバーに応じたRepositoryの一式を生成する
+// DBのスキーマが最新のマイグレーションより古い場合はエラーを返却する
+func Open(cfg config.Config) (Repositories, error) {
+	if cfg.DB.Driver == config.DriverMemory {
+		return NewMemory(), nil
+	}
+	dbObj, err := util.OpenDB(cfg)
+	if err != nil {
+		return Repositories{}, err
+	}
+	if err := prepareSchema(cfg, dbObj); err != nil {
+		util.CloseDB(dbObj)
+		return Repositories{}, err
+	}
+	return NewGorm(dbObj), nil
+}
+
+// prepareSchema はDBのスキーマが最新かを確認する
+// SQLiteのインメモリDBは常に空のため全てのマイグレーションを適用する
+func prepareSchema(cfg config.Config, dbObj *gorm.DB) error {
+	migrator, err := migration.New(dbObj, cfg.DB.Driver)
+	if err != nil {
+		return err
+	}
+	if util.IsSQLiteMemory(cfg.DB) {
+		return migrator.Up()
+	}
+	return migrator.CheckUpToDate()
+}
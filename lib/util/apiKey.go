@@ -0,0 +1,53 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// apiKeyPrefix は発行するAPIキーの先頭に付与する識別子
+const apiKeyPrefix = "todo"
+
+// ErrInvalidAPIKey はAPIキーの形式が不正な場合のエラー
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// GenerateAPIKey はAPIキーを生成し、キー本体と検索用のprefix、保存用のハッシュを返却する
+// キーは "todo_<prefix>_<secret>" の形式
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	prefixBytes := make([]byte, 6)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", err
+	}
+	secretBytes := make([]byte, 24)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(prefixBytes)
+	key = apiKeyPrefix + "_" + prefix + "_" + hex.EncodeToString(secretBytes)
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// SplitAPIKeyPrefix はAPIキーから検索用のprefixを取り出す
+func SplitAPIKeyPrefix(key string) (string, error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", ErrInvalidAPIKey
+	}
+	return parts[1], nil
+}
+
+// HashAPIKey はAPIキーを保存用にSHA-256でハッシュ化する
+// キーは十分なエントロピーを持つためストレッチングは行わない
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareAPIKey は保存されたハッシュとAPIキーを一定時間で照合する
+func CompareAPIKey(hash, key string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(HashAPIKey(key))) == 1
+}
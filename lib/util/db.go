@@ -1,40 +1,110 @@
 package util
 
 import (
-	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
-	_ "gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-var dsn string = "host=localhost user=hajime.saito dbname=todo_app port=5432 sslmode=disable"
-var db *gorm.DB
-var err error
+// sqliteMemoryDSN はSQLiteのインメモリDBを指定するDSN
+const sqliteMemoryDSN = ":memory:"
 
-// ConnectDB データベース接続
-func ConnectDB() error {
-	fmt.Println("Connect Database")
-	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	return err
+// OpenDB は設定をもとにコネクションプールを持つデータベース接続を作成する
+// 起動時に1度だけ呼び出し、各ハンドラーで共有する
+func OpenDB(cfg config.Config) (*gorm.DB, error) {
+	dbObj, err := gorm.Open(dialector(cfg.DB), &gorm.Config{
+		Logger: logger.Default.LogMode(GormLogLevel(cfg.Log)),
+		// Note: SQLiteで日時を文字列として比較できるよう、gormが設定する日時もUTCに揃える
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := dbObj.DB()
+	if err != nil {
+		return nil, err
+	}
+	if IsSQLiteMemory(cfg.DB) {
+		// Note: インメモリDBは接続毎に別のDBになるため、1つの接続を使い続ける
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		return dbObj, nil
+	}
+	sqlDB.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
+	return dbObj, nil
 }
 
-// DisconnectTodoDB データベースの接続解除
-func DisconnectDB() {
-	handleDb, err := db.DB()
-	if err != nil {
-		panic("failed to connect database")
+// dialector は設定のDBドライバーに応じたgormのDialectorを返却する
+func dialector(cfg config.DBConfig) gorm.Dialector {
+	switch cfg.Driver {
+	case config.DriverMySQL:
+		return mysql.Open(withParams(cfg.DSN, map[string]string{"parseTime": "true", "loc": "UTC"}))
+	case config.DriverSQLite:
+		return sqlite.Open(withParams(cfg.DSN, map[string]string{"_foreign_keys": "1", "_busy_timeout": "5000"}))
+	default:
+		return postgres.Open(cfg.DSN)
+	}
+}
+
+// withParams はDSNに未指定のパラメーターを追加する
+// MySQLは時刻をtime.Timeとして読み込むため、SQLiteは外部キー制約とロック待ちを有効にするために利用する
+func withParams(dsn string, defaults map[string]string) string {
+	keys := make([]string, 0, len(defaults))
+	for key := range defaults {
+		keys = append(keys, key)
 	}
-	defer handleDb.Close()
-	fmt.Println("Dis-Connect Database")
+	sort.Strings(keys)
+
+	params := []string{}
+	for _, key := range keys {
+		if !strings.Contains(dsn, key+"=") {
+			params = append(params, key+"="+defaults[key])
+		}
+	}
+	if len(params) == 0 {
+		return dsn
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + strings.Join(params, "&")
 }
 
-// GetDbObj データベースObjectの取得
-func GetDbObj() *gorm.DB {
-	return db
+// IsSQLiteMemory はSQLiteのインメモリDBを利用する設定かを判定する
+func IsSQLiteMemory(cfg config.DBConfig) bool {
+	return cfg.Driver == config.DriverSQLite && strings.HasPrefix(cfg.DSN, sqliteMemoryDSN)
 }
 
-// テスト用の データベース切り替え
-func UseTestBD() {
-	dsn = "host=localhost user=hajime.saito dbname=todo_app_test port=5432 sslmode=disable"
+// CloseDB データベースの接続解除
+func CloseDB(dbObj *gorm.DB) error {
+	sqlDB, err := dbObj.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// GormLogLevel は設定のログレベルをgormのログレベルに変換する
+func GormLogLevel(cfg config.LogConfig) logger.LogLevel {
+	switch cfg.Level {
+	case config.LogLevelDebug:
+		return logger.Info
+	case config.LogLevelInfo, config.LogLevelWarn:
+		return logger.Warn
+	default:
+		return logger.Error
+	}
 }
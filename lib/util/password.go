@@ -0,0 +1,69 @@
+package util
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash はユーザーが存在しない場合にも照合時間を揃えるためのハッシュ
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// HashPassword はパスワードをbcryptでハッシュ化する
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// IsHashedPassword は保存されている値がbcryptのハッシュかを判定する
+func IsHashedPassword(stored string) bool {
+	if !strings.HasPrefix(stored, "$2a$") && !strings.HasPrefix(stored, "$2b$") && !strings.HasPrefix(stored, "$2y$") {
+		return false
+	}
+	_, err := bcrypt.Cost([]byte(stored))
+	return err == nil
+}
+
+// ComparePassword は保存されている値とパスワードを一定時間で照合する
+// 平文で保存されている古いデータの場合も一定時間比較で照合する
+func ComparePassword(stored, password string) bool {
+	if IsHashedPassword(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+}
+
+// CompareDummyPassword はユーザーが存在しない場合に照合時間を揃えるためのダミー照合
+func CompareDummyPassword(password string) {
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+// CheckPasswordStrength はパスワードの強度を検証する
+// 8文字以上72バイト以下で、英字と数字をそれぞれ1文字以上含む必要がある
+func CheckPasswordStrength(password string) error {
+	if len(password) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+	if len(password) > 72 {
+		return errors.New("password must be at most 72 bytes")
+	}
+	hasLetter, hasDigit := false, false
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return errors.New("password must contain both letters and digits")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// AccessTokenType はAPIアクセス用トークンの種別
+	AccessTokenType = "access"
+	// RefreshTokenType はアクセストークン再発行用トークンの種別
+	RefreshTokenType = "refresh"
+)
+
+// ErrInvalidToken はトークンの検証に失敗した場合のエラー
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenClaims は発行するトークンのClaims
+type TokenClaims struct {
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// UserID はトークンの対象ユーザーのIDを返却する
+func (c TokenClaims) UserID() (uint, error) {
+	id, err := strconv.ParseUint(c.Subject, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return uint(id), nil
+}
+
+// IssuedBefore はトークンが指定の日時より前に発行されたかを判定する
+// パスワードの変更前に発行されたトークンを拒否するために利用し、日時がnilの場合はfalseを返却する
+// Note: 発行日時は秒単位のため、指定の日時も秒単位に切り捨てて比較する
+func (c TokenClaims) IssuedBefore(t *time.Time) bool {
+	if t == nil || c.IssuedAt == nil {
+		return false
+	}
+	return c.IssuedAt.Time.Before(t.Truncate(time.Second))
+}
+
+// TokenTTL は指定種別のトークンの有効期間を返却する
+func TokenTTL(cfg config.AuthConfig, tokenType string) time.Duration {
+	if tokenType == RefreshTokenType {
+		return cfg.RefreshTokenTTL
+	}
+	return cfg.AccessTokenTTL
+}
+
+// IssueToken は指定ユーザーの指定種別のトークンをHS256で署名して発行する
+func IssueToken(cfg config.AuthConfig, userID uint, tokenType string) (string, TokenClaims, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", TokenClaims{}, err
+	}
+	now := time.Now()
+	claims := TokenClaims{
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL(cfg, tokenType))),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.Secret))
+	return signed, claims, err
+}
+
+// ParseToken はトークンの署名と有効期限、種別を検証してClaimsを返却する
+func ParseToken(cfg config.AuthConfig, tokenString string, tokenType string) (TokenClaims, error) {
+	claims := TokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, ErrInvalidToken
+		}
+		return []byte(cfg.Secret), nil
+	})
+	if err != nil || claims.Type != tokenType || claims.ID == "" {
+		return TokenClaims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
@@ -1,11 +1,70 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	// Note: タイムゾーンのデータがない環境でも timezone の指定を解釈できるよう埋め込む
+	_ "time/tzdata"
+
 	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/repository"
 )
 
 func main() {
-	// db.ConnectDb()
+	configPath := flag.String("config", "", "path to YAML config file (default: $TODO_CONFIG_FILE)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "migrate" {
+			log.Fatalf("unknown command %q", args[0])
+		}
+		if err := runMigrate(cfg, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	repos, err := repository.Open(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer repos.Close()
+
 	api.Test()
-	api.Router().Run("localhost:8080")
+	server := &http.Server{
+		Addr:         cfg.Server.Addr,
+		Handler:      api.Router(cfg, repos),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+	}
+
+	// Note: SIGINT/SIGTERMを受けたら処理中のリクエストを待って停止する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go runTrashPurge(ctx, cfg.Trash, repos.Todos)
+	go runTokenPurge(ctx, cfg.Auth, repos.Tokens)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Println("failed to shutdown server:", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
 }
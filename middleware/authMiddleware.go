@@ -1,43 +1,135 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 
-	"github.com/Z-me/practice-todo-api/lib/db"
+	"github.com/Z-me/practice-todo-api/api/model"
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/repository"
 	"github.com/Z-me/practice-todo-api/lib/util"
 	"github.com/gin-gonic/gin"
 )
 
-func LoginCheckMiddleware() gin.HandlerFunc {
+// gin.Contextに認証情報を格納する際のキー
+const (
+	loginUserKey  = "loginUser"
+	authScopeKey  = "authScope"
+	apiKeyAuthKey = "apiKeyAuth"
+)
+
+// apiKeyHeader はAPIキーを指定するリクエストヘッダー
+const apiKeyHeader = "X-API-Key"
+
+// 401の際に返却するWWW-Authenticateヘッダーの値 (RFC 7617, RFC 6750)
+const (
+	basicChallenge  = `Basic realm="todo", charset="UTF-8"`
+	bearerChallenge = `Bearer realm="todo"`
+)
+
+// LoginCheckMiddleware はBasic認証、BearerトークンまたはAPIキーでユーザーを認証する
+func LoginCheckMiddleware(cfg config.Config, repos repository.Repositories) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key := c.Request.Header.Get(apiKeyHeader); key != "" {
+			apiKeyAuth(c, repos.APIKeys, key)
+			return
+		}
+
 		auth := c.Request.Header.Get("Authorization")
-		if auth == "" || !strings.HasPrefix(auth, "Basic") {
-			c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
-			c.Abort()
+		if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+			bearerAuth(c, cfg, repos.Users, strings.TrimSpace(auth[7:]))
 			return
 		}
-		util.ConnectDB()
-		defer util.DisconnectDB()
-		dbObj := util.GetDbObj()
-
-		fmt.Println("auth: ", auth)
-		token := auth[6:]
-		fmt.Println("token: ", token)
-		splittedAuth := strings.Split(token, ":")
-		if len(splittedAuth) != 2 {
-			c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+
+		name, password, ok := c.Request.BasicAuth()
+		if !ok {
+			unauthorized(c)
+			return
+		}
+		user, ok := repos.Users.CheckUserAuth(c.Request.Context(), name, password)
+		if !ok {
+			unauthorized(c)
+			return
+		}
+		setLoginUser(c, user, model.APIKeyScopeReadWrite, false)
+		c.Next()
+	}
+}
+
+// bearerAuth はアクセストークンを検証してユーザーを認証する
+// パスワードの変更前に発行されたトークンは拒否する
+func bearerAuth(c *gin.Context, cfg config.Config, users repository.UserRepository, token string) {
+	claims, err := util.ParseToken(cfg.Auth, token, util.AccessTokenType)
+	if err != nil {
+		unauthorized(c)
+		return
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		unauthorized(c)
+		return
+	}
+	user, err := users.GetUserByID(c.Request.Context(), userID)
+	if err != nil || claims.IssuedBefore(user.PasswordChangedAt) {
+		unauthorized(c)
+		return
+	}
+	setLoginUser(c, user, model.APIKeyScopeReadWrite, false)
+	c.Next()
+}
+
+// apiKeyAuth はAPIキーを検証してユーザーを認証する
+func apiKeyAuth(c *gin.Context, apiKeys repository.APIKeyRepository, key string) {
+	user, apiKey, ok := apiKeys.CheckAPIKey(c.Request.Context(), key)
+	if !ok {
+		unauthorized(c)
+		return
+	}
+	setLoginUser(c, user, apiKey.Scope, true)
+	c.Next()
+}
+
+// setLoginUser は認証されたユーザーと権限範囲をgin.Contextに格納する
+func setLoginUser(c *gin.Context, user model.User, scope string, viaAPIKey bool) {
+	c.Set(loginUserKey, user)
+	c.Set(authScopeKey, scope)
+	c.Set(apiKeyAuthKey, viaAPIKey)
+}
+
+// RequireWriteScope は読み取り専用のAPIキーによる更新系のリクエストを拒否する
+func RequireWriteScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(authScopeKey) != model.APIKeyScopeReadWrite {
+			c.IndentedJSON(http.StatusForbidden, gin.H{"message": "403 Forbidden: read-only api key"})
 			c.Abort()
 			return
 		}
-		name := splittedAuth[0]
-		password := splittedAuth[1]
-		if !db.CheckUserAuth(dbObj, name, password) {
-			c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+		c.Next()
+	}
+}
+
+// RequireUserCredential はAPIキーによるアカウント及びAPIキー管理のリクエストを拒否する
+func RequireUserCredential() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool(apiKeyAuthKey) {
+			c.IndentedJSON(http.StatusForbidden, gin.H{"message": "403 Forbidden: api key is not allowed"})
 			c.Abort()
-		} else {
-			c.Next()
+			return
 		}
+		c.Next()
 	}
 }
+
+// unauthorized は認証チャレンジを付与して401を返却する
+func unauthorized(c *gin.Context) {
+	c.Writer.Header().Add("WWW-Authenticate", basicChallenge)
+	c.Writer.Header().Add("WWW-Authenticate", bearerChallenge)
+	c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "401 Unauthorized"})
+	c.Abort()
+}
+
+// GetLoginUser はLoginCheckMiddlewareで認証されたユーザーを取得する
+func GetLoginUser(c *gin.Context) model.User {
+	user, _ := c.MustGet(loginUserKey).(model.User)
+	return user
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/util"
+	"github.com/Z-me/practice-todo-api/migration"
+)
+
+const migrateUsage = "usage: migrate up|down|status|to N|force N"
+
+// runMigrate は migrate サブコマンドを実行する
+func runMigrate(cfg config.Config, args []string) error {
+	if cfg.DB.Driver == config.DriverMemory {
+		return errors.New("migrate: memory driver does not need migrations")
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	dbObj, err := util.OpenDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer util.CloseDB(dbObj)
+	migrator, err := migration.New(dbObj, cfg.DB.Driver)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = migrator.Up()
+	case args[0] == "down" && len(args) == 1:
+		err = migrator.Down()
+	case args[0] == "status" && len(args) == 1:
+		return printMigrationStatus(migrator)
+	case (args[0] == "to" || args[0] == "force") && len(args) == 2:
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			return errors.New(migrateUsage)
+		}
+		if args[0] == "to" {
+			err = migrator.To(version)
+		} else {
+			err = migrator.Force(version)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+	current, err := migrator.Current()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("schema version: %d (latest: %d)\n", current, migrator.Latest())
+	return nil
+}
+
+// printMigrationStatus はマイグレーションの適用状況を出力する
+func printMigrationStatus(migrator *migration.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = "applied at " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%03d %-40s %s\n", s.Version, s.Name, appliedAt)
+	}
+	return nil
+}
@@ -1 +0,0 @@
-ALTER TABLE todos ADD COLUMN user_id SERIAL NOT NULL;
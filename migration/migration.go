@@ -0,0 +1,291 @@
+package migration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// files はDBドライバー毎のマイグレーションのSQL
+// ドライバー名のディレクトリに "<連番>_<名前>_up.sql" と "<連番>_<名前>_down.sql" の組で格納する
+//
+//go:embed postgres/*.sql mysql/*.sql sqlite/*.sql
+var files embed.FS
+
+// ErrSchemaBehind はDBのスキーマが最新のマイグレーションより古い場合のエラー
+var ErrSchemaBehind = errors.New("migration: database schema is behind")
+
+// Migration は1つのバージョンのマイグレーション
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status はマイグレーションの適用状況
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// SchemaMigration は適用済みのマイグレーションを記録するテーブル
+type SchemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time
+}
+
+// Migrator は指定のDBにマイグレーションを適用する
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// Load は指定のDBドライバーのマイグレーションをバージョン順に読み込む
+func Load(driver string) ([]Migration, error) {
+	names, err := fs.Glob(files, driver+"/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("migration: no migrations for driver %q", driver)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, name := range names {
+		base := path.Base(name)
+		var direction string
+		switch {
+		case strings.HasSuffix(base, "_up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, "_down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration: %s: must end with _up.sql or _down.sql", name)
+		}
+		parts := strings.SplitN(strings.TrimSuffix(base, "_"+direction+".sql"), "_", 2)
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 {
+			return nil, fmt.Errorf("migration: %s: must start with a version number", name)
+		}
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration: %s/%03d_%s: both up and down files are required", driver, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// New は指定のDBドライバーのマイグレーションを読み込んでMigratorを生成する
+// 適用状況を記録するテーブルが存在しない場合は作成する
+func New(dbObj *gorm.DB, driver string) (*Migrator, error) {
+	migrations, err := Load(driver)
+	if err != nil {
+		return nil, err
+	}
+	if err := dbObj.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER NOT NULL PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL
+)`).Error; err != nil {
+		return nil, fmt.Errorf("migration: %w", err)
+	}
+	return &Migrator{db: dbObj, migrations: migrations}, nil
+}
+
+// Latest は最新のマイグレーションのバージョンを返却する
+func (m *Migrator) Latest() int {
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Current は適用済みの最新のバージョンを返却する (未適用の場合は0)
+func (m *Migrator) Current() (int, error) {
+	applied := SchemaMigration{}
+	err := m.db.Order("version DESC").Limit(1).Find(&applied).Error
+	return applied.Version, err
+}
+
+// Status は全てのマイグレーションの適用状況を返却する
+func (m *Migrator) Status() ([]Status, error) {
+	applied := []SchemaMigration{}
+	if err := m.db.Find(&applied).Error; err != nil {
+		return nil, err
+	}
+	appliedAt := map[int]time.Time{}
+	for _, v := range applied {
+		appliedAt[v.Version] = v.AppliedAt
+	}
+
+	result := []Status{}
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// Up は未適用の全てのマイグレーションを適用する
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// Down は適用済みの最新のマイグレーションを1つ取り消す
+func (m *Migrator) Down() error {
+	current, err := m.Current()
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return nil
+	}
+	target := 0
+	for _, migration := range m.migrations {
+		if migration.Version < current {
+			target = migration.Version
+		}
+	}
+	return m.To(target)
+}
+
+// To は指定のバージョンまでマイグレーションを適用または取り消す
+func (m *Migrator) To(version int) error {
+	if version != 0 && !m.exists(version) {
+		return fmt.Errorf("migration: unknown version %d", version)
+	}
+	current, err := m.Current()
+	if err != nil {
+		return err
+	}
+
+	if version >= current {
+		for _, migration := range m.migrations {
+			if migration.Version > current && migration.Version <= version {
+				if err := m.apply(migration, migration.Up, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version <= current && migration.Version > version {
+			if err := m.apply(migration, migration.Down, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Force はマイグレーションを実行せずに指定のバージョンまで適用済みとして記録する
+// 本ツール導入前に手動でマイグレーションを適用したDBで利用する
+func (m *Migrator) Force(version int) error {
+	if version != 0 && !m.exists(version) {
+		return fmt.Errorf("migration: unknown version %d", version)
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&SchemaMigration{}).Error; err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if err := tx.Create(&SchemaMigration{Version: migration.Version, AppliedAt: time.Now()}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CheckUpToDate はDBのスキーマが最新かを確認する
+func (m *Migrator) CheckUpToDate() error {
+	current, err := m.Current()
+	if err != nil {
+		return err
+	}
+	if current < m.Latest() {
+		return fmt.Errorf("%w: current version %d, latest version %d (run `migrate up`)", ErrSchemaBehind, current, m.Latest())
+	}
+	return nil
+}
+
+func (m *Migrator) exists(version int) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// apply はマイグレーションのSQLと適用状況の記録を1つのトランザクションで実行する
+func (m *Migrator) apply(migration Migration, content string, up bool) error {
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(content) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		if up {
+			return tx.Create(&SchemaMigration{Version: migration.Version, AppliedAt: time.Now()}).Error
+		}
+		return tx.Delete(&SchemaMigration{}, migration.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration: %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// splitStatements はSQLを ";" で区切られた文に分割し、コメント行と空の文を取り除く
+func splitStatements(content string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	stmts := []string{}
+	for _, stmt := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
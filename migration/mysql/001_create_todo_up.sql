@@ -0,0 +1,9 @@
+CREATE TABLE todo (
+    id INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    title VARCHAR(50) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    details TEXT NOT NULL,
+    priority VARCHAR(10) NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    updated_at DATETIME(6) NOT NULL
+);
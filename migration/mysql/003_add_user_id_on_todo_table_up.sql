@@ -0,0 +1 @@
+ALTER TABLE todo ADD COLUMN user_id INTEGER NOT NULL DEFAULT 0;
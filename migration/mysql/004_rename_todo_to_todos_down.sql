@@ -0,0 +1 @@
+RENAME TABLE todos TO todo;
@@ -0,0 +1 @@
+RENAME TABLE todo TO todos;
@@ -0,0 +1,7 @@
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) NOT NULL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    expires_at DATETIME(6) NOT NULL,
+    created_at DATETIME(6) NOT NULL
+);
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
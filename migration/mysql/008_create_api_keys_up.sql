@@ -0,0 +1,14 @@
+CREATE TABLE api_keys (
+    id INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    scope VARCHAR(10) NOT NULL,
+    expires_at DATETIME(6),
+    last_used_at DATETIME(6),
+    created_at DATETIME(6) NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX api_keys_prefix_key ON api_keys (prefix);
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,16 @@
+ALTER TABLE todos MODIFY status VARCHAR(20) NOT NULL;
+ALTER TABLE todos ADD COLUMN started_at DATETIME(6);
+ALTER TABLE todos ADD COLUMN completed_at DATETIME(6);
+-- 既存のStatusを既定のワークフローのStatusに変換する
+-- 対応しないStatusはそのまま残し、任意のStatusへ遷移できるものとして扱う
+UPDATE todos SET status = 'todo' WHERE LOWER(status) IN ('todo', 'new', 'open');
+UPDATE todos SET status = 'in_progress' WHERE LOWER(status) IN ('doing', 'in progress', 'inprogress', 'in_progress', 'wip');
+UPDATE todos SET status = 'blocked' WHERE LOWER(status) = 'blocked';
+UPDATE todos SET status = 'done', completed_at = updated_at WHERE LOWER(status) IN ('done', 'complete', 'completed', 'closed');
+UPDATE todos SET status = 'cancelled' WHERE LOWER(status) IN ('cancelled', 'canceled');
+CREATE TABLE user_workflows (
+    user_id INTEGER NOT NULL PRIMARY KEY,
+    definition TEXT NOT NULL,
+    updated_at DATETIME(6) NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
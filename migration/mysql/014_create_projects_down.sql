@@ -0,0 +1,4 @@
+ALTER TABLE todos DROP FOREIGN KEY todos_project_id_fkey;
+DROP INDEX todos_project_id_idx ON todos;
+ALTER TABLE todos DROP COLUMN project_id;
+DROP TABLE projects;
@@ -0,0 +1,14 @@
+CREATE TABLE projects (
+    id INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME(6) NOT NULL,
+    updated_at DATETIME(6) NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX projects_user_id_name_key ON projects (user_id, name);
+ALTER TABLE todos ADD COLUMN project_id INTEGER;
+CREATE INDEX todos_project_id_idx ON todos (project_id);
+ALTER TABLE todos ADD CONSTRAINT todos_project_id_fkey FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE SET NULL;
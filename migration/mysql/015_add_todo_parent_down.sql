@@ -0,0 +1,3 @@
+ALTER TABLE todos DROP FOREIGN KEY todos_parent_id_fkey;
+DROP INDEX todos_parent_id_idx ON todos;
+ALTER TABLE todos DROP COLUMN parent_id;
@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN parent_id INTEGER;
+CREATE INDEX todos_parent_id_idx ON todos (parent_id);
+ALTER TABLE todos ADD CONSTRAINT todos_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES todos (id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE todo_dependencies (
+    todo_id INTEGER NOT NULL,
+    blocker_id INTEGER NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (todo_id, blocker_id),
+    FOREIGN KEY (todo_id) REFERENCES todos (id) ON DELETE CASCADE,
+    FOREIGN KEY (blocker_id) REFERENCES todos (id) ON DELETE CASCADE
+);
+CREATE INDEX todo_dependencies_blocker_id_idx ON todo_dependencies (blocker_id);
@@ -0,0 +1,2 @@
+DROP INDEX todos_deleted_at_idx ON todos;
+ALTER TABLE todos DROP COLUMN deleted_at;
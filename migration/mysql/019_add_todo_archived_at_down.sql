@@ -0,0 +1,2 @@
+DROP INDEX todos_user_id_archived_at_idx ON todos;
+ALTER TABLE todos DROP COLUMN archived_at;
@@ -0,0 +1,2 @@
+ALTER TABLE todos ADD COLUMN archived_at DATETIME(6);
+CREATE INDEX todos_user_id_archived_at_idx ON todos (user_id, archived_at);
@@ -0,0 +1 @@
+ALTER TABLE todos RENAME TO todo;
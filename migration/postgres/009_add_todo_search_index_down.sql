@@ -0,0 +1 @@
+DROP INDEX todos_search_idx;
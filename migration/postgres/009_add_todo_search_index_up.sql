@@ -0,0 +1,2 @@
+-- lib/db/searchDb.go の検索と同じ式でインデックスを作成する
+CREATE INDEX todos_search_idx ON todos USING GIN (to_tsvector('simple', title || ' ' || details));
@@ -0,0 +1,7 @@
+DROP TABLE user_workflows;
+-- VARCHAR(10)に収まらないStatusは切り詰める
+UPDATE todos SET status = 'doing' WHERE status = 'in_progress';
+UPDATE todos SET status = SUBSTR(status, 1, 10);
+ALTER TABLE todos DROP COLUMN completed_at;
+ALTER TABLE todos DROP COLUMN started_at;
+ALTER TABLE todos ALTER COLUMN status TYPE VARCHAR(10);
@@ -0,0 +1,15 @@
+ALTER TABLE todos ALTER COLUMN status TYPE VARCHAR(20);
+ALTER TABLE todos ADD COLUMN started_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE todos ADD COLUMN completed_at TIMESTAMP WITH TIME ZONE;
+-- 既存のStatusを既定のワークフローのStatusに変換する
+-- 対応しないStatusはそのまま残し、任意のStatusへ遷移できるものとして扱う
+UPDATE todos SET status = 'todo' WHERE LOWER(status) IN ('todo', 'new', 'open');
+UPDATE todos SET status = 'in_progress' WHERE LOWER(status) IN ('doing', 'in progress', 'inprogress', 'in_progress', 'wip');
+UPDATE todos SET status = 'blocked' WHERE LOWER(status) = 'blocked';
+UPDATE todos SET status = 'done', completed_at = updated_at WHERE LOWER(status) IN ('done', 'complete', 'completed', 'closed');
+UPDATE todos SET status = 'cancelled' WHERE LOWER(status) IN ('cancelled', 'canceled');
+CREATE TABLE user_workflows (
+    user_id INTEGER NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    definition TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP INDEX todos_user_id_due_at_idx;
+ALTER TABLE todos DROP COLUMN due_at;
+ALTER TABLE todos DROP COLUMN start_at;
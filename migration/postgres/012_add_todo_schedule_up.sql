@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN start_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE todos ADD COLUMN due_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX todos_user_id_due_at_idx ON todos (user_id, due_at);
@@ -0,0 +1,12 @@
+CREATE TABLE projects (
+    id SERIAL NOT NULL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE UNIQUE INDEX projects_user_id_name_key ON projects (user_id, name);
+ALTER TABLE todos ADD COLUMN project_id INTEGER REFERENCES projects (id) ON DELETE SET NULL;
+CREATE INDEX todos_project_id_idx ON todos (project_id);
@@ -0,0 +1,2 @@
+ALTER TABLE todos ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX todos_deleted_at_idx ON todos (deleted_at);
@@ -0,0 +1,9 @@
+CREATE TABLE todo (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(50) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    details TEXT NOT NULL,
+    priority VARCHAR(10) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE todo RENAME TO todos;
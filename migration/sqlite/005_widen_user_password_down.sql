@@ -0,0 +1 @@
+-- SQLiteはVARCHARの長さを制限しないため変更不要
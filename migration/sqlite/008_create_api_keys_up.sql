@@ -0,0 +1,13 @@
+CREATE TABLE api_keys (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    scope VARCHAR(10) NOT NULL,
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    created_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX api_keys_prefix_key ON api_keys (prefix);
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
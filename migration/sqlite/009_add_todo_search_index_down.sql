@@ -0,0 +1 @@
+-- 全文検索はPostgreSQLのみ対応し、他のDBはLIKEで検索するため変更不要
@@ -0,0 +1,4 @@
+DROP TABLE user_workflows;
+UPDATE todos SET status = 'doing' WHERE status = 'in_progress';
+ALTER TABLE todos DROP COLUMN completed_at;
+ALTER TABLE todos DROP COLUMN started_at;
@@ -0,0 +1 @@
+-- 変換前のPriorityは復元できないため変更しない
@@ -0,0 +1,9 @@
+-- 既存のPriorityを P0 (最優先) から P4 (最低) の5段階に変換する
+-- 解釈できない値は既定の P2 とする
+UPDATE todos SET priority = UPPER(TRIM(priority)) WHERE UPPER(TRIM(priority)) IN ('P0', 'P1', 'P2', 'P3', 'P4');
+UPDATE todos SET priority = 'P0' WHERE LOWER(TRIM(priority)) IN ('0', 'urgent', 'critical', 'highest');
+UPDATE todos SET priority = 'P1' WHERE LOWER(TRIM(priority)) IN ('1', 'high');
+UPDATE todos SET priority = 'P2' WHERE LOWER(TRIM(priority)) IN ('2', 'medium', 'normal');
+UPDATE todos SET priority = 'P3' WHERE LOWER(TRIM(priority)) IN ('3', 'low');
+UPDATE todos SET priority = 'P4' WHERE LOWER(TRIM(priority)) IN ('4', 'lowest', 'trivial');
+UPDATE todos SET priority = 'P2' WHERE priority NOT IN ('P0', 'P1', 'P2', 'P3', 'P4');
@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN start_at DATETIME;
+ALTER TABLE todos ADD COLUMN due_at DATETIME;
+CREATE INDEX todos_user_id_due_at_idx ON todos (user_id, due_at);
@@ -0,0 +1,13 @@
+CREATE TABLE projects (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX projects_user_id_name_key ON projects (user_id, name);
+-- SQLiteは外部キー制約のあるカラムを削除できないため、プロジェクト削除時の処理はアプリケーションで行う
+ALTER TABLE todos ADD COLUMN project_id INTEGER;
+CREATE INDEX todos_project_id_idx ON todos (project_id);
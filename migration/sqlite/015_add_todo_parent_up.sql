@@ -0,0 +1,3 @@
+-- SQLiteは外部キー制約のあるカラムを削除できないため、親の削除時の処理はアプリケーションで行う
+ALTER TABLE todos ADD COLUMN parent_id INTEGER;
+CREATE INDEX todos_parent_id_idx ON todos (parent_id);
@@ -0,0 +1,7 @@
+CREATE TABLE todo_dependencies (
+    todo_id INTEGER NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    blocker_id INTEGER NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (todo_id, blocker_id)
+);
+CREATE INDEX todo_dependencies_blocker_id_idx ON todo_dependencies (blocker_id);
@@ -0,0 +1 @@
+ALTER TABLE todos ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
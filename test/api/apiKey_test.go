@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestAPIKey(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	ts := httptest.NewServer(api.Router(cfg, openTestRepos(t, cfg)))
+	defer ts.Close()
+
+	do := func(t *testing.T, method, url, header, value, payload string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.URL+url, bytes.NewBuffer([]byte(payload)))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		req.Header.Set(header, value)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return res
+	}
+
+	// Note: 事前処理
+	res := do(t, "POST", "/api-keys", "Authorization", getAuth(), `{"name": "ci", "scope": "read"}`)
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("[Create] Expected status code %v, got %v", http.StatusCreated, res.StatusCode)
+	}
+	var created handler.APIKey
+	json.NewDecoder(res.Body).Decode(&created)
+	if created.Key == "" {
+		t.Fatalf("[Create] want key to be shown once, got empty")
+	}
+
+	cases := []struct {
+		name    string
+		url     string
+		method  string
+		header  string
+		value   string
+		payload string
+		status  int
+	}{
+		{
+			name:   "正常系: 読み取り専用キーでTodoList取得",
+			url:    "/todo",
+			method: "GET",
+			header: "X-API-Key",
+			value:  created.Key,
+			status: http.StatusOK,
+		},
+		{
+			name:    "異常系: 読み取り専用キーで新規追加: 403",
+			url:     "/todo",
+			method:  "POST",
+			header:  "X-API-Key",
+			value:   created.Key,
+			payload: `{"title": "Test TODO", "status": "done", "details": "test_todo", "priority": "P0"}`,
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "異常系: APIキーでAPIキー発行: 403",
+			url:     "/api-keys",
+			method:  "POST",
+			header:  "X-API-Key",
+			value:   created.Key,
+			payload: `{"name": "escalate", "scope": "read_write"}`,
+			status:  http.StatusForbidden,
+		},
+		{
+			name:   "異常系: 不正なキー: 401",
+			url:    "/todo",
+			method: "GET",
+			header: "X-API-Key",
+			value:  "todo_000000000000_invalid",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "正常系: APIキー失効",
+			url:    "/api-keys/" + strconv.Itoa(created.ID),
+			method: "DELETE",
+			header: "Authorization",
+			value:  getAuth(),
+			status: http.StatusOK,
+		},
+		{
+			name:   "異常系: 失効済みキー: 401",
+			url:    "/todo",
+			method: "GET",
+			header: "X-API-Key",
+			value:  created.Key,
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(caseNameHelper(t, c.name, c.method, c.url), func(t *testing.T) {
+			res := do(t, c.method, c.url, c.header, c.value, c.payload)
+			defer res.Body.Close()
+			if res.StatusCode != c.status {
+				t.Fatalf("Expected status code %v, got %v", c.status, res.StatusCode)
+			}
+		})
+	}
+}
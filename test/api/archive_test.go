@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestArchive(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (Todoをアーカイブするため専用のユーザーを利用する)
+	_, auth := newTestUser(t, repos, "archive_test")
+
+	ids := []int{}
+	for _, status := range []string{"done", "done", "todo", "cancelled"} {
+		status, body := sendRequest(t, ts, "POST", "/todo", auth, `{"title": "Archive", "status": "`+status+`", "priority": "P2"}`)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		ids = append(ids, resData.ID)
+	}
+	listIDs := func(t *testing.T, url string) []int {
+		t.Helper()
+		status, body := sendRequest(t, ts, "GET", url, auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData []handler.Todo
+		json.Unmarshal(body, &resData)
+		result := []int{}
+		for _, v := range resData {
+			result = append(result, v.ID)
+		}
+		return result
+	}
+	archiveCompleted := func(t *testing.T, payload string) handler.ArchiveResult {
+		t.Helper()
+		status, body := sendRequest(t, ts, "POST", "/todo/archive-completed", auth, payload)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData handler.ArchiveResult
+		json.Unmarshal(body, &resData)
+		return resData
+	}
+
+	t.Run(caseNameHelper(t, "正常系: 期間内に完了したItem", "POST", "/todo/archive-completed"), func(t *testing.T) {
+		if got := archiveCompleted(t, `{"older_than_days": 1}`); got.Count != 0 || len(got.IDs) != 0 {
+			t.Fatalf("Archived: want none, got %+v", got)
+		}
+	})
+
+	t.Run(caseNameHelper(t, "正常系: 完了した全てのItem", "POST", "/todo/archive-completed"), func(t *testing.T) {
+		got := archiveCompleted(t, `{"older_than_days": 0}`)
+		if got.Count != 2 || fmt.Sprint(got.IDs) != fmt.Sprint(ids[:2]) {
+			t.Fatalf("Archived: want %v, got %+v", ids[:2], got)
+		}
+		if got := listIDs(t, "/todo"); fmt.Sprint(got) != fmt.Sprint(ids[2:]) {
+			t.Fatalf("Todo: want %v, got %v", ids[2:], got)
+		}
+		if got := listIDs(t, "/todo?archived=true"); fmt.Sprint(got) != fmt.Sprint(ids[:2]) {
+			t.Fatalf("Archived todo: want %v, got %v", ids[:2], got)
+		}
+		if got := archiveCompleted(t, `{"older_than_days": 0}`); got.Count != 0 {
+			t.Fatalf("Archived: want none, got %+v", got)
+		}
+	})
+
+	t.Run(caseNameHelper(t, "正常系: アーカイブの変更", "PUT", "/todo/:id"), func(t *testing.T) {
+		status, body := sendRequest(t, ts, "PUT", "/todo/"+strconv.Itoa(ids[0]), auth, `{"title": "Archive", "status": "done", "priority": "P2", "archived": false}`)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		if resData.ArchivedAt != nil {
+			t.Fatalf("ArchivedAt: want nil, got %v", resData.ArchivedAt)
+		}
+		status, body = sendRequest(t, ts, "PUT", "/todo/"+strconv.Itoa(ids[2]), auth, `{"title": "Archive", "status": "todo", "priority": "P2", "archived": true}`)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		json.Unmarshal(body, &resData)
+		if resData.ArchivedAt == nil {
+			t.Fatalf("ArchivedAt: want not nil, got nil")
+		}
+		if got := listIDs(t, "/todo"); fmt.Sprint(got) != fmt.Sprint([]int{ids[0], ids[3]}) {
+			t.Fatalf("Todo: want %v, got %v", []int{ids[0], ids[3]}, got)
+		}
+		if got := listIDs(t, "/todo?archived=true&status=todo"); fmt.Sprint(got) != fmt.Sprint([]int{ids[2]}) {
+			t.Fatalf("Archived todo: want %v, got %v", []int{ids[2]}, got)
+		}
+	})
+
+	errorCases := []struct {
+		name    string
+		method  string
+		url     string
+		payload string
+	}{
+		{"不正なアーカイブの指定", "GET", "/todo?archived=maybe", ""},
+		{"日数の省略", "POST", "/todo/archive-completed", `{}`},
+		{"負の日数", "POST", "/todo/archive-completed", `{"older_than_days": -1}`},
+	}
+	for _, tc := range errorCases {
+		t.Run(caseNameHelper(t, "異常系: "+tc.name, tc.method, tc.url), func(t *testing.T) {
+			if status, body := sendRequest(t, ts, tc.method, tc.url, auth, tc.payload); status != http.StatusBadRequest {
+				t.Fatalf("Expected status code %v, got %v: %s", http.StatusBadRequest, status, body)
+			}
+		})
+	}
+}
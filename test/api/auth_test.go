@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestBasicAuth(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	ts := httptest.NewServer(api.Router(cfg, openTestRepos(t, cfg)))
+	defer ts.Close()
+
+	cases := []struct {
+		name      string
+		url       string
+		method    string
+		auth      string
+		status    int
+		challenge bool
+	}{
+		{
+			name:      "正常系: Basic認証",
+			url:       "/todo",
+			method:    "GET",
+			auth:      getAuth(),
+			status:    http.StatusOK,
+			challenge: false,
+		},
+		{
+			name:      "異常系: 認証ヘッダーなし: 401",
+			url:       "/todo",
+			method:    "GET",
+			auth:      "",
+			status:    http.StatusUnauthorized,
+			challenge: true,
+		},
+		{
+			name:      "異常系: base64エンコードされていない認証情報: 401",
+			url:       "/todo",
+			method:    "GET",
+			auth:      "Basic test:password",
+			status:    http.StatusUnauthorized,
+			challenge: true,
+		},
+		{
+			name:      "異常系: パスワード誤り: 401",
+			url:       "/todo",
+			method:    "GET",
+			auth:      "Basic dGVzdDp3cm9uZzpwYXNzd29yZA==",
+			status:    http.StatusUnauthorized,
+			challenge: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(caseNameHelper(t, c.name, c.method, c.url), func(t *testing.T) {
+			client := &http.Client{}
+			req, err := http.NewRequest(c.method, ts.URL+c.url, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if c.auth != "" {
+				req.Header.Set("Authorization", c.auth)
+			}
+
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != c.status {
+				t.Fatalf("Expected status code %v, got %v", c.status, res.StatusCode)
+			}
+			if c.challenge && res.Header.Get("WWW-Authenticate") == "" {
+				t.Fatalf("WWW-Authenticate: want challenge header, got none")
+			}
+		})
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	ts := httptest.NewServer(api.Router(cfg, openTestRepos(t, cfg)))
+	defer ts.Close()
+
+	post := func(t *testing.T, url string, payload string) (*http.Response, handler.Token) {
+		t.Helper()
+		res, err := http.Post(ts.URL+url, "application/json", bytes.NewBuffer([]byte(payload)))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer res.Body.Close()
+		var token handler.Token
+		json.NewDecoder(res.Body).Decode(&token)
+		return res, token
+	}
+
+	res, issued := post(t, "/auth/token", `{"name": "test", "password": "password"}`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("[Issue] Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if issued.TokenType != "Bearer" || issued.AccessToken == "" || issued.RefreshToken == "" {
+		t.Fatalf("[Issue] want token pair, got %v", issued)
+	}
+
+	res, _ = post(t, "/auth/token", `{"name": "test", "password": "wrong"}`)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("[Issue] Expected status code %v, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+
+	req, err := http.NewRequest("GET", ts.URL+"/todo", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+issued.AccessToken)
+	bearerRes, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bearerRes.Body.Close()
+	if bearerRes.StatusCode != http.StatusOK {
+		t.Fatalf("[Bearer] Expected status code %v, got %v", http.StatusOK, bearerRes.StatusCode)
+	}
+
+	res, refreshed := post(t, "/auth/refresh", `{"refresh_token": "`+issued.RefreshToken+`"}`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("[Refresh] Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	res, _ = post(t, "/auth/refresh", `{"refresh_token": "`+issued.RefreshToken+`"}`)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("[Refresh: reuse] Expected status code %v, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+
+	res, _ = post(t, "/auth/logout", `{"refresh_token": "`+refreshed.RefreshToken+`"}`)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("[Logout] Expected status code %v, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	res, _ = post(t, "/auth/refresh", `{"refresh_token": "`+refreshed.RefreshToken+`"}`)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("[Refresh: after logout] Expected status code %v, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestRefreshTokenRevocation(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (パスワードを変更するため専用のユーザーを利用する)
+	user, auth := newTestUser(t, repos, "token_test")
+	ctx := context.Background()
+	issue := func(t *testing.T, password string) handler.Token {
+		t.Helper()
+		status, body := sendRequest(t, ts, "POST", "/auth/token", "", `{"name": "token_test", "password": "`+password+`"}`)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var token handler.Token
+		json.Unmarshal(body, &token)
+		return token
+	}
+
+	t.Run(caseNameHelper(t, "異常系: 同時の再発行", "POST", "/auth/refresh"), func(t *testing.T) {
+		issued := issue(t, "passw0rd123")
+		const n = 5
+		statuses := make([]int, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				res, err := http.Post(ts.URL+"/auth/refresh", "application/json", bytes.NewBufferString(`{"refresh_token": "`+issued.RefreshToken+`"}`))
+				if err != nil {
+					return
+				}
+				res.Body.Close()
+				statuses[i] = res.StatusCode
+			}(i)
+		}
+		wg.Wait()
+		succeeded := 0
+		for _, status := range statuses {
+			if status == http.StatusOK {
+				succeeded++
+			} else if status != http.StatusUnauthorized {
+				t.Fatalf("Expected status code %v or %v, got %v", http.StatusOK, http.StatusUnauthorized, statuses)
+			}
+		}
+		if succeeded != 1 {
+			t.Fatalf("Refresh: want exactly one success, got %v", statuses)
+		}
+	})
+
+	t.Run(caseNameHelper(t, "異常系: パスワード変更前のトークン", "POST", "/auth/refresh"), func(t *testing.T) {
+		issued := issue(t, "passw0rd123")
+		// Note: トークンの発行日時は秒単位のため、次の秒になってからパスワードを変更する
+		time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+		if status, body := sendRequest(t, ts, "PATCH", "/me", auth, `{"password": "newpassw0rd", "current_password": "passw0rd123"}`); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		if status, body := sendRequest(t, ts, "POST", "/auth/refresh", "", `{"refresh_token": "`+issued.RefreshToken+`"}`); status != http.StatusUnauthorized {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusUnauthorized, status, body)
+		}
+		if status, body := sendRequest(t, ts, "GET", "/todo", "Bearer "+issued.AccessToken, ""); status != http.StatusUnauthorized {
+			t.Fatalf("Access token: Expected status code %v, got %v: %s", http.StatusUnauthorized, status, body)
+		}
+		reissued := issue(t, "newpassw0rd")
+		if status, body := sendRequest(t, ts, "GET", "/todo", "Bearer "+reissued.AccessToken, ""); status != http.StatusOK {
+			t.Fatalf("Access token: Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		if status, body := sendRequest(t, ts, "POST", "/auth/refresh", "", `{"refresh_token": "`+reissued.RefreshToken+`"}`); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+	})
+
+	t.Run("有効期限が切れた失効済みトークンの削除", func(t *testing.T) {
+		for _, v := range []struct {
+			jti       string
+			expiresAt time.Time
+		}{
+			{"expired-token", time.Now().Add(-time.Hour)},
+			{"active-token", time.Now().Add(time.Hour)},
+		} {
+			if revoked, err := repos.Tokens.RevokeToken(ctx, v.jti, user.ID, v.expiresAt); err != nil || !revoked {
+				t.Fatalf("RevokeToken: want revoked, got %v, %v", revoked, err)
+			}
+		}
+		if revoked, err := repos.Tokens.RevokeToken(ctx, "active-token", user.ID, time.Now().Add(time.Hour)); err != nil || revoked {
+			t.Fatalf("RevokeToken: want already revoked, got %v, %v", revoked, err)
+		}
+		if count, err := repos.Tokens.PurgeRevokedTokens(ctx, time.Now()); err != nil || count < 1 {
+			t.Fatalf("PurgeRevokedTokens: want purged, got %v, %v", count, err)
+		}
+		if revoked, _ := repos.Tokens.IsTokenRevoked(ctx, "expired-token"); revoked {
+			t.Fatalf("IsTokenRevoked: want expired token purged")
+		}
+		if revoked, _ := repos.Tokens.IsTokenRevoked(ctx, "active-token"); !revoked {
+			t.Fatalf("IsTokenRevoked: want active token kept")
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestBulk(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (Todoを一括で変更するため専用のユーザーを利用する)
+	_, auth := newTestUser(t, repos, "bulk_test")
+
+	status, body := sendRequest(t, ts, "POST", "/todo", auth, `{"title": "Bulk", "status": "todo", "priority": "P2"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+	}
+	var target handler.Todo
+	json.Unmarshal(body, &target)
+	targetID := strconv.Itoa(target.ID)
+
+	countTodos := func(t *testing.T) int {
+		t.Helper()
+		status, body := sendRequest(t, ts, "GET", "/todo", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData []handler.Todo
+		json.Unmarshal(body, &resData)
+		return len(resData)
+	}
+	resultStatuses := func(res handler.BulkResponse) string {
+		statuses := []int{}
+		for _, v := range res.Results {
+			statuses = append(statuses, v.Status)
+		}
+		return fmt.Sprint(statuses)
+	}
+
+	cases := []struct {
+		name     string
+		payload  string
+		status   int
+		results  string
+		count    int
+		expected func(t *testing.T)
+	}{
+		{
+			name: "正常系: atomic",
+			payload: `{"operations": [
+				{"op": "create", "todo": {"title": "Bulk1", "status": "todo", "priority": "P1"}},
+				{"op": "create", "todo": {"title": "Bulk2", "status": "todo", "priority": "P3"}},
+				{"op": "update", "id": ` + targetID + `, "todo": {"title": "Bulk Updated", "status": "todo", "priority": "P2"}},
+				{"op": "status", "id": ` + targetID + `, "status": "in_progress"}
+			]}`,
+			status:  http.StatusOK,
+			results: "[201 201 200 200]",
+			count:   3,
+			expected: func(t *testing.T) {
+				status, body := sendRequest(t, ts, "GET", "/todo/"+targetID, auth, "")
+				var resData handler.Todo
+				json.Unmarshal(body, &resData)
+				if status != http.StatusOK || resData.Title != "Bulk Updated" || resData.Status != "in_progress" {
+					t.Fatalf("Unexpected item: %v %s", status, body)
+				}
+			},
+		},
+		{
+			name: "異常系: atomic で存在しないItemの操作を含む場合は全て取り消す",
+			payload: `{"mode": "atomic", "operations": [
+				{"op": "create", "todo": {"title": "Rollback", "status": "todo", "priority": "P1"}},
+				{"op": "delete", "id": 99999999},
+				{"op": "delete", "id": ` + targetID + `}
+			]}`,
+			status:  http.StatusNotFound,
+			results: "[424 404 424]",
+			count:   3,
+		},
+		{
+			name: "異常系: atomic で検証に失敗した操作を含む場合は実行しない",
+			payload: `{"operations": [
+				{"op": "create", "todo": {"title": "Invalid", "status": "todo", "priority": "P9"}},
+				{"op": "move", "id": ` + targetID + `},
+				{"op": "status", "id": ` + targetID + `}
+			]}`,
+			status:  http.StatusBadRequest,
+			results: "[400 400 400]",
+			count:   3,
+		},
+		{
+			name: "正常系: best_effort は成功した操作のみ反映する",
+			payload: `{"mode": "best_effort", "operations": [
+				{"op": "create", "todo": {"title": "Partial", "status": "todo", "priority": "P1"}},
+				{"op": "update", "id": 99999999, "todo": {"title": "Missing", "status": "todo", "priority": "P1"}},
+				{"op": "create", "todo": {"title": "", "status": "todo", "priority": "P1"}},
+				{"op": "delete", "id": ` + targetID + `}
+			]}`,
+			status:  http.StatusMultiStatus,
+			results: "[201 404 400 200]",
+			count:   3,
+		},
+		{
+			name:    "正常系: best_effort で全て成功",
+			payload: `{"mode": "best_effort", "operations": [{"op": "create", "todo": {"title": "All", "status": "todo", "priority": "P1"}}]}`,
+			status:  http.StatusOK,
+			results: "[201]",
+			count:   4,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(caseNameHelper(t, c.name, "POST", "/todo/bulk"), func(t *testing.T) {
+			status, body := sendRequest(t, ts, "POST", "/todo/bulk", auth, c.payload)
+			if status != c.status {
+				t.Fatalf("Expected status code %v, got %v: %s", c.status, status, body)
+			}
+			var resData handler.BulkResponse
+			json.Unmarshal(body, &resData)
+			if got := resultStatuses(resData); got != c.results {
+				t.Fatalf("Results: want %s, got %s: %s", c.results, got, body)
+			}
+			if got := countTodos(t); got != c.count {
+				t.Fatalf("Todo count: want %v, got %v", c.count, got)
+			}
+			if c.expected != nil {
+				c.expected(t)
+			}
+		})
+	}
+
+	errorCases := []struct {
+		name    string
+		payload string
+	}{
+		{name: "異常系: 不正なモード", payload: `{"mode": "partial", "operations": [{"op": "delete", "id": 1}]}`},
+		{name: "異常系: 空の操作", payload: `{"operations": []}`},
+		{name: "異常系: 操作の省略", payload: `{"mode": "atomic"}`},
+	}
+	for _, c := range errorCases {
+		t.Run(caseNameHelper(t, c.name, "POST", "/todo/bulk"), func(t *testing.T) {
+			if status, body := sendRequest(t, ts, "POST", "/todo/bulk", auth, c.payload); status != http.StatusBadRequest {
+				t.Fatalf("Expected status code %v, got %v: %s", http.StatusBadRequest, status, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestDependencies(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (依存関係を変更するため専用のユーザーを利用する)
+	_, auth := newTestUser(t, repos, "dependency_test")
+
+	ids := []int{}
+	for _, title := range []string{"Design", "Implement", "Release"} {
+		status, body := sendRequest(t, ts, "POST", "/todo", auth, `{"title": "`+title+`", "status": "todo", "priority": "P2"}`)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		ids = append(ids, resData.ID)
+	}
+	design, implement, release := ids[0], ids[1], ids[2]
+	dependencyURL := func(id int) string {
+		return "/todo/" + strconv.Itoa(id) + "/dependencies"
+	}
+	getTodo := func(t *testing.T, id int) handler.Todo {
+		t.Helper()
+		_, body := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(id), auth, "")
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		return resData
+	}
+
+	// Note: Release は Implement を、Implement は Design を待つ
+	addCases := []struct {
+		name      string
+		id        int
+		blockerID int
+		status    int
+	}{
+		{"正常系: 依存関係の追加", implement, design, http.StatusCreated},
+		{"正常系: 依存関係の追加", release, implement, http.StatusCreated},
+		{"正常系: 追加済みの依存関係", release, implement, http.StatusCreated},
+		{"異常系: 自身への依存", design, design, http.StatusBadRequest},
+		{"異常系: 循環する依存", design, release, http.StatusBadRequest},
+		{"異常系: 存在しないBlocker", design, 99999, http.StatusBadRequest},
+		{"異常系: 存在しないItem", 99999, design, http.StatusNotFound},
+	}
+	for _, tc := range addCases {
+		t.Run(caseNameHelper(t, tc.name, "POST", dependencyURL(tc.id)), func(t *testing.T) {
+			payload := fmt.Sprintf(`{"blocker_id": %d}`, tc.blockerID)
+			if status, body := sendRequest(t, ts, "POST", dependencyURL(tc.id), auth, payload); status != tc.status {
+				t.Fatalf("Expected status code %v, got %v: %s", tc.status, status, body)
+			}
+		})
+	}
+
+	t.Run("正常系: BlockerとDependentの表示", func(t *testing.T) {
+		got := getTodo(t, implement)
+		if fmt.Sprint(got.BlockedBy) != fmt.Sprint([]int{design}) || fmt.Sprint(got.Blocking) != fmt.Sprint([]int{release}) {
+			t.Fatalf("Dependencies: want [%v] / [%v], got %v / %v", design, release, got.BlockedBy, got.Blocking)
+		}
+	})
+
+	t.Run("異常系: 終了していないBlockerを持つItemの完了", func(t *testing.T) {
+		status, body := sendRequest(t, ts, "PATCH", "/todo/"+strconv.Itoa(release)+"/status", auth, `{"status": "done"}`)
+		if status != http.StatusConflict {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusConflict, status, body)
+		}
+		var resData struct {
+			OpenBlockers []int `json:"open_blockers"`
+		}
+		json.Unmarshal(body, &resData)
+		if fmt.Sprint(resData.OpenBlockers) != fmt.Sprint([]int{implement}) {
+			t.Fatalf("OpenBlockers: want [%v], got %v", implement, resData.OpenBlockers)
+		}
+		// Note: 終了していないStatusへの変更は可能
+		if status, _ := sendRequest(t, ts, "PATCH", "/todo/"+strconv.Itoa(release)+"/status", auth, `{"status": "in_progress"}`); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+	})
+
+	t.Run("正常系: Blockerの終了後の完了", func(t *testing.T) {
+		for _, id := range []int{design, implement} {
+			if status, body := sendRequest(t, ts, "PATCH", "/todo/"+strconv.Itoa(id)+"/status", auth, `{"status": "done"}`); status != http.StatusOK {
+				t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+			}
+		}
+		if status, body := sendRequest(t, ts, "PATCH", "/todo/"+strconv.Itoa(release)+"/status", auth, `{"status": "done"}`); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+	})
+
+	t.Run("正常系: 依存関係の削除", func(t *testing.T) {
+		url := dependencyURL(release) + "/" + strconv.Itoa(implement)
+		if status, _ := sendRequest(t, ts, "DELETE", url, auth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+		if status, _ := sendRequest(t, ts, "DELETE", url, auth, ""); status != http.StatusNotFound {
+			t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, status)
+		}
+		if got := getTodo(t, implement); len(got.Blocking) != 0 {
+			t.Fatalf("Blocking: want [], got %v", got.Blocking)
+		}
+	})
+
+	t.Run("正常系: Blockerの削除", func(t *testing.T) {
+		if status, _ := sendRequest(t, ts, "DELETE", "/todo/"+strconv.Itoa(design), auth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+		if got := getTodo(t, implement); len(got.BlockedBy) != 0 {
+			t.Fatalf("BlockedBy: want [], got %v", got.BlockedBy)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+	"github.com/Z-me/practice-todo-api/api/model"
+)
+
+func TestFilterTodoList(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
+	noon := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	seeds := []model.Payload{
+		{Title: "Write report", Status: "todo", Details: "", Priority: "P1"},
+		{Title: "Review 100%_done", Status: "in_progress", Details: "", Priority: "P2"},
+		{Title: "Buy milk", Status: "done", Details: "", Priority: "P1"},
+		{Title: "Noon deadline", Status: "cancelled", Details: "", Priority: "P3", DueAt: &noon},
+	}
+	ids := map[string]int{}
+	for _, p := range seeds {
+		item, err := repos.Todos.AddNewTodo(ctx, userID, p)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		ids[p.Title] = int(item.ID)
+	}
+	future := url.QueryEscape(time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	past := url.QueryEscape(time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+	jst := time.FixedZone("JST", 9*60*60)
+	futureJST := url.QueryEscape(time.Now().Add(time.Hour).In(jst).Format(time.RFC3339))
+	pastJST := url.QueryEscape(time.Now().Add(-time.Hour).In(jst).Format(time.RFC3339))
+	auth := getAuth()
+
+	cases := []struct {
+		name     string
+		url      string
+		status   int
+		expected []int
+		invalid  []string
+	}{
+		{
+			name:     "正常系: Statusの複数指定",
+			url:      "/todo?status=todo&status=in_progress",
+			status:   http.StatusOK,
+			expected: []int{ids["Write report"], ids["Review 100%_done"]},
+		},
+		{
+			name:     "正常系: Statusのカンマ区切り指定",
+			url:      "/todo?status=todo,done",
+			status:   http.StatusOK,
+			expected: []int{ids["Write report"], ids["Buy milk"]},
+		},
+		{
+			name:     "正常系: PriorityとStatusの組み合わせ",
+			url:      "/todo?priority=P1&status=done",
+			status:   http.StatusOK,
+			expected: []int{ids["Buy milk"]},
+		},
+		{
+			name:     "正常系: Titleの部分一致(大文字小文字を区別しない)",
+			url:      "/todo?title=MILK",
+			status:   http.StatusOK,
+			expected: []int{ids["Buy milk"]},
+		},
+		{
+			name:     "正常系: TitleのLIKE特殊文字",
+			url:      "/todo?title=" + url.QueryEscape("0%_"),
+			status:   http.StatusOK,
+			expected: []int{ids["Review 100%_done"]},
+		},
+		{
+			name:     "正常系: 作成日時の範囲",
+			url:      "/todo?priority=P2&created_after=" + past + "&created_before=" + future,
+			status:   http.StatusOK,
+			expected: []int{ids["Review 100%_done"]},
+		},
+		{
+			name:     "正常系: タイムゾーン付きの作成日時の範囲",
+			url:      "/todo?priority=P2&created_after=" + pastJST + "&created_before=" + futureJST,
+			status:   http.StatusOK,
+			expected: []int{ids["Review 100%_done"]},
+		},
+		{
+			name:     "正常系: タイムゾーン付きの範囲外の更新日時",
+			url:      "/todo?priority=P2&updated_before=" + pastJST,
+			status:   http.StatusOK,
+			expected: []int{},
+		},
+		{
+			name:     "正常系: 範囲外の更新日時",
+			url:      "/todo?updated_after=" + future,
+			status:   http.StatusOK,
+			expected: []int{},
+		},
+		{
+			// Note: 2026-01-01 20:00 +09:00 は 11:00Z、22:00 +09:00 は 13:00Z
+			name:     "正常系: タイムゾーン付きの期限の範囲",
+			url:      "/todo?title=Noon&due_after=" + url.QueryEscape("2026-01-01T20:00:00+09:00") + "&due_before=" + url.QueryEscape("2026-01-01T22:00:00+09:00"),
+			status:   http.StatusOK,
+			expected: []int{ids["Noon deadline"]},
+		},
+		{
+			name:     "正常系: タイムゾーン付きの範囲外の期限",
+			url:      "/todo?title=Noon&due_before=" + url.QueryEscape("2026-01-01T20:00:00+09:00"),
+			status:   http.StatusOK,
+			expected: []int{},
+		},
+		{
+			name:    "異常系: 不正なパラメータ",
+			url:     "/todo?status=&created_after=yesterday&title=%20",
+			status:  http.StatusBadRequest,
+			invalid: []string{"status", "created_after", "title"},
+		},
+		{
+			name:    "異常系: ワークフローにないStatus",
+			url:     "/todo?status=todo,typo",
+			status:  http.StatusBadRequest,
+			invalid: []string{"status"},
+		},
+		{
+			name:    "異常系: 不正なPriority",
+			url:     "/todo?priority=P1,high",
+			status:  http.StatusBadRequest,
+			invalid: []string{"priority"},
+		},
+		{
+			name:    "異常系: 逆転した日時の範囲",
+			url:     "/todo?updated_after=" + future + "&updated_before=" + past,
+			status:  http.StatusBadRequest,
+			invalid: []string{"updated_before"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(caseNameHelper(t, c.name, "GET", c.url), func(t *testing.T) {
+			req, err := http.NewRequest("GET", ts.URL+c.url, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			req.Header.Set("Authorization", auth)
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != c.status {
+				t.Fatalf("Expected status code %v, got %v", c.status, res.StatusCode)
+			}
+			if c.invalid != nil {
+				var resData struct {
+					InvalidParams []string `json:"invalid_params"`
+				}
+				json.NewDecoder(res.Body).Decode(&resData)
+				if len(resData.InvalidParams) != len(c.invalid) {
+					t.Fatalf("Invalid params: want %v, got %v", c.invalid, resData.InvalidParams)
+				}
+				for i, v := range c.invalid {
+					if resData.InvalidParams[i] != v {
+						t.Fatalf("Invalid params: want %v, got %v", c.invalid, resData.InvalidParams)
+					}
+				}
+				return
+			}
+
+			var resData []handler.Todo
+			json.NewDecoder(res.Body).Decode(&resData)
+			if len(resData) != len(c.expected) {
+				t.Fatalf("Length: want %v items, got %v", c.expected, resData)
+			}
+			for i, id := range c.expected {
+				if resData[i].ID != id {
+					t.Fatalf("Contents: want %v, got %v", c.expected, resData)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+	"github.com/Z-me/practice-todo-api/api/model"
+)
+
+func TestPaginateTodoList(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
+	ids := []int{}
+	for _, priority := range []model.Priority{"P2", "P1", "P3", "P1", "P2"} {
+		item, err := repos.Todos.AddNewTodo(ctx, userID, model.Payload{Title: "Paging", Status: "todo", Priority: priority})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		ids = append(ids, int(item.ID))
+	}
+
+	t.Run(caseNameHelper(t, "正常系: ページ送り", "GET", "/todo?limit=2&sort=-priority,id"), func(t *testing.T) {
+		// Note: 降順では優先度の高い P1 から並ぶ
+		expected := []int{ids[1], ids[3], ids[0], ids[4], ids[2]}
+		got := []int{}
+		pages := 0
+		for url := "/todo?title=Paging&limit=2&sort=-priority,id"; url != ""; pages++ {
+			var items []handler.Todo
+			items, url = getTodoPage(t, ts, url)
+			for _, v := range items {
+				got = append(got, v.ID)
+			}
+		}
+		if pages != 3 {
+			t.Fatalf("Pages: want 3, got %v", pages)
+		}
+		if len(got) != len(expected) {
+			t.Fatalf("Contents: want %v, got %v", expected, got)
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Fatalf("Contents: want %v, got %v", expected, got)
+			}
+		}
+	})
+
+	t.Run(caseNameHelper(t, "正常系: 優先度の低い順", "GET", "/todo?sort=priority"), func(t *testing.T) {
+		items, _ := getTodoPage(t, ts, "/todo?title=Paging&sort=priority")
+		expected := []int{ids[2], ids[0], ids[4], ids[1], ids[3]}
+		if len(items) != len(expected) {
+			t.Fatalf("Contents: want %v, got %v", expected, items)
+		}
+		for i := range expected {
+			if items[i].ID != expected[i] {
+				t.Fatalf("Contents: want %v, got %v", expected, items)
+			}
+		}
+	})
+
+	t.Run(caseNameHelper(t, "正常系: ページ間の追加と削除", "GET", "/todo?limit=2&sort=-priority"), func(t *testing.T) {
+		first, next := getTodoPage(t, ts, "/todo?title=Paging&limit=2&sort=-priority")
+		if len(first) != 2 || first[0].ID != ids[1] || first[1].ID != ids[3] || next == "" {
+			t.Fatalf("Unexpected first page: %v, next = %v", first, next)
+		}
+		// Note: 取得済みの範囲への追加と、取得済みItemの削除はページに影響しない
+		if _, err := repos.Todos.AddNewTodo(ctx, userID, model.Payload{Title: "Paging", Status: "todo", Priority: "P0"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := repos.Todos.DeleteItem(ctx, userID, uint(ids[1])); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		second, _ := getTodoPage(t, ts, next)
+		if len(second) != 2 || second[0].ID != ids[0] || second[1].ID != ids[4] {
+			t.Fatalf("Unexpected second page: %v", second)
+		}
+	})
+
+	t.Run(caseNameHelper(t, "正常系: 日時での並び替え", "GET", "/todo?limit=1&sort=-created_at"), func(t *testing.T) {
+		expected, err := repos.Todos.GetTodoList(ctx, userID, model.TodoFilter{Title: "Paging"}, model.TodoPage{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		seen := map[int]bool{}
+		for url := "/todo?title=Paging&limit=1&sort=-created_at"; url != ""; {
+			var items []handler.Todo
+			items, url = getTodoPage(t, ts, url)
+			for _, v := range items {
+				if seen[v.ID] {
+					t.Fatalf("Duplicated item: %v", v.ID)
+				}
+				seen[v.ID] = true
+			}
+		}
+		if len(seen) != len(expected) {
+			t.Fatalf("Length: want %v items, got %v", len(expected), len(seen))
+		}
+	})
+
+	cases := []struct {
+		name    string
+		url     string
+		invalid []string
+	}{
+		{
+			name:    "異常系: 不正なlimit",
+			url:     "/todo?limit=0",
+			invalid: []string{"limit"},
+		},
+		{
+			name:    "異常系: 不正なsort",
+			url:     "/todo?sort=-details",
+			invalid: []string{"sort"},
+		},
+		{
+			name:    "異常系: 不正なcursor",
+			url:     "/todo?cursor=not-a-cursor",
+			invalid: []string{"cursor"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(caseNameHelper(t, c.name, "GET", c.url), func(t *testing.T) {
+			req, err := http.NewRequest("GET", ts.URL+c.url, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			req.Header.Set("Authorization", getAuth())
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+			var resData struct {
+				InvalidParams []string `json:"invalid_params"`
+			}
+			json.NewDecoder(res.Body).Decode(&resData)
+			if len(resData.InvalidParams) != 1 || resData.InvalidParams[0] != c.invalid[0] {
+				t.Fatalf("Invalid params: want %v, got %v", c.invalid, resData.InvalidParams)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+	"github.com/Z-me/practice-todo-api/api/model"
+)
+
+func TestProjects(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (プロジェクトを変更するため専用のユーザーを利用する)
+	_, auth := newTestUser(t, repos, "project_test")
+
+	createProject := func(t *testing.T, payload string) handler.Project {
+		t.Helper()
+		status, body := sendRequest(t, ts, "POST", "/projects", auth, payload)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+		}
+		var resData handler.Project
+		json.Unmarshal(body, &resData)
+		return resData
+	}
+	createTodo := func(t *testing.T, projectID int) handler.Todo {
+		t.Helper()
+		payload := `{"title": "Project item", "status": "todo", "priority": "P2"}`
+		if projectID != 0 {
+			payload = fmt.Sprintf(`{"title": "Project item", "status": "todo", "priority": "P2", "project_id": %d}`, projectID)
+		}
+		status, body := sendRequest(t, ts, "POST", "/todo", auth, payload)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		return resData
+	}
+	getTodoIDs := func(t *testing.T, url string) []int {
+		t.Helper()
+		status, body := sendRequest(t, ts, "GET", url, auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData []handler.Todo
+		json.Unmarshal(body, &resData)
+		ids := []int{}
+		for _, v := range resData {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+
+	backend := createProject(t, `{"name": " backend ", "color": "#FF8800"}`)
+	frontend := createProject(t, `{"name": "frontend"}`)
+	inbox := createTodo(t, 0)
+	backendTodo := createTodo(t, backend.ID)
+	frontendTodo := createTodo(t, frontend.ID)
+
+	t.Run("正常系: プロジェクトの作成", func(t *testing.T) {
+		if backend.Name != "backend" || backend.Color != "#ff8800" || backend.Archived {
+			t.Fatalf("Project: unexpected %+v", backend)
+		}
+		if frontend.Color != model.DefaultProjectColor {
+			t.Fatalf("Color: want %v, got %v", model.DefaultProjectColor, frontend.Color)
+		}
+		if backendTodo.ProjectID == nil || *backendTodo.ProjectID != backend.ID {
+			t.Fatalf("ProjectID: want %v, got %v", backend.ID, backendTodo.ProjectID)
+		}
+		if inbox.ProjectID != nil {
+			t.Fatalf("ProjectID: want nil, got %v", *inbox.ProjectID)
+		}
+	})
+
+	invalidCases := []struct {
+		name, method, url, payload string
+		status                     int
+	}{
+		{"重複した名前", "POST", "/projects", `{"name": "backend"}`, http.StatusConflict},
+		{"空の名前", "POST", "/projects", `{"name": "  "}`, http.StatusBadRequest},
+		{"不正な色", "POST", "/projects", `{"name": "other", "color": "red"}`, http.StatusBadRequest},
+		{"重複した名前への変更", "PATCH", "/projects/" + strconv.Itoa(frontend.ID), `{"name": "backend"}`, http.StatusConflict},
+		{"存在しないプロジェクトの更新", "PATCH", "/projects/99999", `{"name": "other"}`, http.StatusNotFound},
+		{"存在しないプロジェクトへのTodoの追加", "POST", "/todo", `{"title": "x", "status": "todo", "priority": "P2", "project_id": 99999}`, http.StatusBadRequest},
+		{"存在しないプロジェクトのTodoリスト", "GET", "/projects/99999/todos", "", http.StatusNotFound},
+		{"不正な削除方法", "DELETE", "/projects/" + strconv.Itoa(frontend.ID) + "?todos=move", "", http.StatusBadRequest},
+	}
+	for _, tc := range invalidCases {
+		t.Run(caseNameHelper(t, "異常系: "+tc.name, tc.method, tc.url), func(t *testing.T) {
+			if status, body := sendRequest(t, ts, tc.method, tc.url, auth, tc.payload); status != tc.status {
+				t.Fatalf("Expected status code %v, got %v: %s", tc.status, status, body)
+			}
+		})
+	}
+
+	t.Run("正常系: プロジェクト毎のTodoリスト", func(t *testing.T) {
+		if got := getTodoIDs(t, "/projects/"+strconv.Itoa(backend.ID)+"/todos"); fmt.Sprint(got) != fmt.Sprint([]int{backendTodo.ID}) {
+			t.Fatalf("Todo: want [%v], got %v", backendTodo.ID, got)
+		}
+		if got := getTodoIDs(t, "/todo?project_id=0"); fmt.Sprint(got) != fmt.Sprint([]int{inbox.ID}) {
+			t.Fatalf("Todo: want [%v], got %v", inbox.ID, got)
+		}
+	})
+
+	t.Run("正常系: プロジェクトの移動", func(t *testing.T) {
+		payload := fmt.Sprintf(`{"title": "Moved", "status": "todo", "priority": "P2", "project_id": %d}`, backend.ID)
+		if status, body := sendRequest(t, ts, "PUT", "/todo/"+strconv.Itoa(inbox.ID), auth, payload); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		// Note: project_id を省略した場合はプロジェクトを変更しない
+		payload = `{"title": "Moved again", "status": "todo", "priority": "P2"}`
+		if status, body := sendRequest(t, ts, "PUT", "/todo/"+strconv.Itoa(inbox.ID), auth, payload); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		if got := getTodoIDs(t, "/projects/"+strconv.Itoa(backend.ID)+"/todos"); fmt.Sprint(got) != fmt.Sprint([]int{inbox.ID, backendTodo.ID}) {
+			t.Fatalf("Todo: want [%v %v], got %v", inbox.ID, backendTodo.ID, got)
+		}
+		payload = `{"title": "Moved back", "status": "todo", "priority": "P2", "project_id": 0}`
+		if status, body := sendRequest(t, ts, "PUT", "/todo/"+strconv.Itoa(inbox.ID), auth, payload); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+	})
+
+	t.Run("正常系: プロジェクトのアーカイブ", func(t *testing.T) {
+		status, body := sendRequest(t, ts, "PATCH", "/projects/"+strconv.Itoa(frontend.ID), auth, `{"archived": true}`)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var projects []handler.Project
+		_, body = sendRequest(t, ts, "GET", "/projects", auth, "")
+		json.Unmarshal(body, &projects)
+		if len(projects) != 1 || projects[0].ID != backend.ID {
+			t.Fatalf("Projects: want [%v], got %+v", backend.ID, projects)
+		}
+		_, body = sendRequest(t, ts, "GET", "/projects?archived=true", auth, "")
+		json.Unmarshal(body, &projects)
+		if len(projects) != 1 || projects[0].ID != frontend.ID {
+			t.Fatalf("Projects: want [%v], got %+v", frontend.ID, projects)
+		}
+		payload := fmt.Sprintf(`{"title": "x", "status": "todo", "priority": "P2", "project_id": %d}`, frontend.ID)
+		if status, _ := sendRequest(t, ts, "POST", "/todo", auth, payload); status != http.StatusBadRequest {
+			t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, status)
+		}
+	})
+
+	t.Run("正常系: プロジェクトの削除", func(t *testing.T) {
+		if status, _ := sendRequest(t, ts, "DELETE", "/projects/"+strconv.Itoa(backend.ID), auth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+		status, body := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(backendTodo.ID), auth, "")
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		if status != http.StatusOK || resData.ProjectID != nil {
+			t.Fatalf("Todo: want kept without project, got %v %s", status, body)
+		}
+
+		if status, _ := sendRequest(t, ts, "DELETE", "/projects/"+strconv.Itoa(frontend.ID)+"?todos=delete", auth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+		if status, _ := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(frontendTodo.ID), auth, ""); status != http.StatusNotFound {
+			t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, status)
+		}
+	})
+}
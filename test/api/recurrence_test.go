@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestRecurrence(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (繰り返しのTodoを作成するため専用のユーザーを利用する)
+	_, auth := newTestUser(t, repos, "recurrence_test")
+
+	createTodo := func(t *testing.T, payload string) handler.Todo {
+		t.Helper()
+		status, body := sendRequest(t, ts, "POST", "/todo", auth, payload)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		return resData
+	}
+	findTodoList := func(t *testing.T, title string) []handler.Todo {
+		t.Helper()
+		status, body := sendRequest(t, ts, "GET", "/todo?title="+url.QueryEscape(title), auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData []handler.Todo
+		json.Unmarshal(body, &resData)
+		return resData
+	}
+	complete := func(t *testing.T, id int) {
+		t.Helper()
+		if status, body := sendRequest(t, ts, "PATCH", "/todo/"+strconv.Itoa(id)+"/status", auth, `{"status": "done"}`); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+	}
+
+	previewCases := []struct {
+		name       string
+		recurrence string
+		dueAt      string
+		expected   []string
+	}{
+		{"毎日", "FREQ=DAILY", "2030-01-01T09:00:00Z", []string{"2030-01-02", "2030-01-03", "2030-01-04"}},
+		{"毎週月曜と水曜", "FREQ=WEEKLY;BYDAY=MO,WE", "2030-01-07T09:00:00Z", []string{"2030-01-09", "2030-01-14", "2030-01-16"}},
+		{"隔週", "RRULE:FREQ=WEEKLY;INTERVAL=2", "2030-01-01T09:00:00Z", []string{"2030-01-15", "2030-01-29", "2030-02-12"}},
+		{"毎月31日", "FREQ=MONTHLY;BYMONTHDAY=31", "2030-01-31T09:00:00Z", []string{"2030-03-31", "2030-05-31", "2030-07-31"}},
+		{"毎月末日", "FREQ=MONTHLY;BYMONTHDAY=-1", "2030-01-31T09:00:00Z", []string{"2030-02-28", "2030-03-31", "2030-04-30"}},
+		{"回数の指定", "FREQ=DAILY;COUNT=3", "2030-01-01T09:00:00Z", []string{"2030-01-02", "2030-01-03"}},
+		{"終了日の指定", "FREQ=DAILY;UNTIL=20300102", "2030-01-01T09:00:00Z", []string{"2030-01-02"}},
+	}
+	for _, tc := range previewCases {
+		t.Run(caseNameHelper(t, "正常系: "+tc.name, "GET", "/todo/:id/occurrences"), func(t *testing.T) {
+			todo := createTodo(t, fmt.Sprintf(`{"title": "Preview", "status": "todo", "priority": "P2", "due_at": %q, "recurrence": %q}`, tc.dueAt, tc.recurrence))
+			status, body := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(todo.ID)+"/occurrences?count=3", auth, "")
+			if status != http.StatusOK {
+				t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+			}
+			var occurrences []handler.Occurrence
+			json.Unmarshal(body, &occurrences)
+			got := []string{}
+			for _, v := range occurrences {
+				got = append(got, v.DueAt.Format("2006-01-02"))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.expected) {
+				t.Fatalf("Occurrences: want %v, got %v", tc.expected, got)
+			}
+		})
+	}
+
+	invalidCases := []struct {
+		name    string
+		payload string
+	}{
+		{"未対応のFREQ", `{"title": "Invalid", "status": "todo", "priority": "P2", "due_at": "2030-01-01", "recurrence": "FREQ=YEARLY"}`},
+		{"未対応のキー", `{"title": "Invalid", "status": "todo", "priority": "P2", "due_at": "2030-01-01", "recurrence": "FREQ=DAILY;BYHOUR=9"}`},
+		{"FREQの省略", `{"title": "Invalid", "status": "todo", "priority": "P2", "due_at": "2030-01-01", "recurrence": "BYDAY=MO"}`},
+		{"不正な曜日", `{"title": "Invalid", "status": "todo", "priority": "P2", "due_at": "2030-01-01", "recurrence": "FREQ=WEEKLY;BYDAY=XX"}`},
+		{"期限の省略", `{"title": "Invalid", "status": "todo", "priority": "P2", "recurrence": "FREQ=DAILY"}`},
+	}
+	for _, tc := range invalidCases {
+		t.Run(caseNameHelper(t, "異常系: "+tc.name, "POST", "/todo"), func(t *testing.T) {
+			if status, body := sendRequest(t, ts, "POST", "/todo", auth, tc.payload); status != http.StatusBadRequest {
+				t.Fatalf("Expected status code %v, got %v: %s", http.StatusBadRequest, status, body)
+			}
+		})
+	}
+
+	t.Run("正常系: 完了時の次のTodoの作成", func(t *testing.T) {
+		todo := createTodo(t, `{"title": "Weekly report", "status": "in_progress", "priority": "P1", "start_at": "2030-01-07T00:00:00Z", "due_at": "2030-01-07T09:00:00Z", "recurrence": "FREQ=WEEKLY", "tags": ["report"]}`)
+		if todo.Recurrence == nil || *todo.Recurrence != "FREQ=WEEKLY;BYDAY=MO" {
+			t.Fatalf("Recurrence: want FREQ=WEEKLY;BYDAY=MO, got %v", todo.Recurrence)
+		}
+		complete(t, todo.ID)
+		todoList := findTodoList(t, "Weekly report")
+		if len(todoList) != 2 {
+			t.Fatalf("Length: want 2, got %v", len(todoList))
+		}
+		done, next := todoList[0], todoList[1]
+		if done.Recurrence != nil {
+			t.Fatalf("Recurrence of completed: want nil, got %v", *done.Recurrence)
+		}
+		if next.Status != "todo" || next.Priority != "P1" || fmt.Sprint(next.Tags) != "[report]" {
+			t.Fatalf("Next: unexpected %+v", next)
+		}
+		if !next.DueAt.Equal(time.Date(2030, 1, 14, 9, 0, 0, 0, time.UTC)) || !next.StartAt.Equal(time.Date(2030, 1, 14, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("Next: want 2030-01-14, got %v - %v", next.StartAt, next.DueAt)
+		}
+		if next.Recurrence == nil || *next.Recurrence != "FREQ=WEEKLY;BYDAY=MO" {
+			t.Fatalf("Recurrence of next: want FREQ=WEEKLY;BYDAY=MO, got %v", next.Recurrence)
+		}
+	})
+
+	t.Run("正常系: タイムゾーンでの計算", func(t *testing.T) {
+		// Note: 期限は 2030-03-08 (金) 23:59:59 PST で、UTCでは土曜日となる。2030-03-10 に夏時間が始まる
+		todo := createTodo(t, `{"title": "Pacific weekly", "status": "todo", "priority": "P2", "due_at": "2030-03-08", "timezone": "America/Los_Angeles", "recurrence": "FREQ=WEEKLY"}`)
+		if todo.Recurrence == nil || *todo.Recurrence != "FREQ=WEEKLY;BYDAY=FR" {
+			t.Fatalf("Recurrence: want FREQ=WEEKLY;BYDAY=FR, got %v", todo.Recurrence)
+		}
+		if todo.Timezone == nil || *todo.Timezone != "America/Los_Angeles" {
+			t.Fatalf("Timezone: want America/Los_Angeles, got %v", todo.Timezone)
+		}
+		status, body := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(todo.ID)+"/occurrences?count=2", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var occurrences []handler.Occurrence
+		json.Unmarshal(body, &occurrences)
+		expected := []time.Time{
+			time.Date(2030, 3, 16, 6, 59, 59, 0, time.UTC),
+			time.Date(2030, 3, 23, 6, 59, 59, 0, time.UTC),
+		}
+		if len(occurrences) != len(expected) || !occurrences[0].DueAt.Equal(expected[0]) || !occurrences[1].DueAt.Equal(expected[1]) {
+			t.Fatalf("Occurrences: want %v, got %s", expected, body)
+		}
+
+		complete(t, todo.ID)
+		todoList := findTodoList(t, "Pacific weekly")
+		if len(todoList) != 2 {
+			t.Fatalf("Length: want 2, got %v", len(todoList))
+		}
+		next := todoList[1]
+		if !next.DueAt.Equal(expected[0]) || next.Timezone == nil || *next.Timezone != "America/Los_Angeles" {
+			t.Fatalf("Next: unexpected due_at %v in %v", next.DueAt, next.Timezone)
+		}
+	})
+
+	t.Run("正常系: 回数の上限", func(t *testing.T) {
+		todo := createTodo(t, `{"title": "Twice", "status": "todo", "priority": "P2", "due_at": "2030-01-01T09:00:00Z", "recurrence": "FREQ=DAILY;COUNT=2"}`)
+		complete(t, todo.ID)
+		todoList := findTodoList(t, "Twice")
+		if len(todoList) != 2 || *todoList[1].Recurrence != "FREQ=DAILY;COUNT=1" {
+			t.Fatalf("Todo: unexpected %+v", todoList)
+		}
+		complete(t, todoList[1].ID)
+		if got := len(findTodoList(t, "Twice")); got != 2 {
+			t.Fatalf("Length: want 2, got %v", got)
+		}
+	})
+
+	t.Run("正常系: 親と共に完了した子の次のTodoの作成", func(t *testing.T) {
+		parent := createTodo(t, `{"title": "Release", "status": "todo", "priority": "P1"}`)
+		child := createTodo(t, fmt.Sprintf(`{"title": "Daily check", "status": "todo", "priority": "P2", "parent_id": %d, "due_at": "2030-01-01T09:00:00Z", "recurrence": "FREQ=DAILY"}`, parent.ID))
+		status, body := sendRequest(t, ts, "PATCH", "/todo/"+strconv.Itoa(parent.ID)+"/status", auth, `{"status": "done", "complete_children": true}`)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		todoList := findTodoList(t, "Daily check")
+		if len(todoList) != 2 {
+			t.Fatalf("Length: want 2, got %v", len(todoList))
+		}
+		done, next := todoList[0], todoList[1]
+		if done.ID != child.ID || done.Status != "done" || done.Recurrence != nil {
+			t.Fatalf("Completed: unexpected %+v", done)
+		}
+		if next.Status != "todo" || !next.DueAt.Equal(time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)) {
+			t.Fatalf("Next: unexpected %+v", next)
+		}
+		if next.Recurrence == nil || *next.Recurrence != "FREQ=DAILY" {
+			t.Fatalf("Recurrence of next: want FREQ=DAILY, got %v", next.Recurrence)
+		}
+	})
+
+	t.Run("正常系: 繰り返しの終了", func(t *testing.T) {
+		todo := createTodo(t, `{"title": "Stopped", "status": "todo", "priority": "P2", "due_at": "2030-01-01T09:00:00Z", "recurrence": "FREQ=DAILY"}`)
+		status, body := sendRequest(t, ts, "DELETE", "/todo/"+strconv.Itoa(todo.ID)+"/recurrence", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		complete(t, todo.ID)
+		if got := len(findTodoList(t, "Stopped")); got != 1 {
+			t.Fatalf("Length: want 1, got %v", got)
+		}
+	})
+}
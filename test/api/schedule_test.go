@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+	"github.com/Z-me/practice-todo-api/api/model"
+)
+
+func TestTodoSchedule(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
+	auth := getAuth()
+	at := func(d time.Duration) *time.Time {
+		t := time.Now().Add(d)
+		return &t
+	}
+	seeds := []model.Payload{
+		{Title: "Overdue", Status: "todo", Priority: "P1", DueAt: at(-time.Hour)},
+		{Title: "Overdue but done", Status: "done", Priority: "P1", DueAt: at(-2 * time.Hour)},
+		{Title: "Due soon", Status: "in_progress", Priority: "P1", StartAt: at(-time.Hour), DueAt: at(10 * time.Hour)},
+		{Title: "Due later", Status: "todo", Priority: "P1", DueAt: at(100 * time.Hour)},
+		{Title: "No due", Status: "todo", Priority: "P1"},
+	}
+	ids := []int{}
+	for _, p := range seeds {
+		item, err := repos.Todos.AddNewTodo(ctx, userID, p)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		ids = append(ids, int(item.ID))
+	}
+
+	listCases := []struct {
+		name     string
+		url      string
+		status   int
+		expected []int
+	}{
+		{
+			name:     "正常系: 期限切れ",
+			url:      "/todo/overdue",
+			status:   http.StatusOK,
+			expected: []int{ids[0]},
+		},
+		{
+			name:     "正常系: 期限が近い (既定の期間)",
+			url:      "/todo/upcoming",
+			status:   http.StatusOK,
+			expected: []int{ids[2]},
+		},
+		{
+			name:     "正常系: 期限が近い (期間指定)",
+			url:      "/todo/upcoming?within=120h",
+			status:   http.StatusOK,
+			expected: []int{ids[2], ids[3]},
+		},
+		{
+			name:   "異常系: 不正な期間",
+			url:    "/todo/upcoming?within=soon",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "異常系: 不正なカーソル",
+			url:    "/todo/overdue?cursor=not-a-cursor",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, c := range listCases {
+		t.Run(caseNameHelper(t, c.name, "GET", c.url), func(t *testing.T) {
+			req, err := http.NewRequest("GET", ts.URL+c.url, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			req.Header.Set("Authorization", auth)
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != c.status {
+				t.Fatalf("Expected status code %v, got %v", c.status, res.StatusCode)
+			}
+			if c.expected == nil {
+				return
+			}
+			var resData []handler.Todo
+			json.NewDecoder(res.Body).Decode(&resData)
+			if len(resData) != len(c.expected) {
+				t.Fatalf("Length: want %v, got %v", c.expected, resData)
+			}
+			for i, id := range c.expected {
+				if resData[i].ID != id {
+					t.Fatalf("Contents: want %v, got %v", c.expected, resData)
+				}
+			}
+		})
+	}
+
+	t.Run(caseNameHelper(t, "正常系: ページ送り", "GET", "/todo/upcoming?within=120h&limit=1"), func(t *testing.T) {
+		expected := []int{ids[2], ids[3]}
+		got := []int{}
+		pages := 0
+		for url := "/todo/upcoming?within=120h&limit=1"; url != ""; pages++ {
+			var items []handler.Todo
+			items, url = getTodoPage(t, ts, url)
+			for _, v := range items {
+				got = append(got, v.ID)
+			}
+		}
+		if pages != 2 || len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] {
+			t.Fatalf("Contents: want %v in 2 pages, got %v in %v pages", expected, got, pages)
+		}
+	})
+
+	createCases := []struct {
+		name    string
+		payload string
+		status  int
+		startAt string
+		dueAt   string
+	}{
+		{
+			name:    "正常系: RFC3339形式の日時",
+			payload: `{"title": "Schedule", "status": "todo", "priority": "P2", "start_at": "2030-01-01T09:00:00+09:00", "due_at": "2030-01-02T18:00:00+09:00"}`,
+			status:  http.StatusCreated,
+			startAt: "2030-01-01T00:00:00Z",
+			dueAt:   "2030-01-02T09:00:00Z",
+		},
+		{
+			name:    "正常系: タイムゾーンを指定した日付",
+			payload: `{"title": "Schedule", "status": "todo", "priority": "P2", "start_at": "2030-01-01", "due_at": "2030-01-02", "timezone": "Asia/Tokyo"}`,
+			status:  http.StatusCreated,
+			startAt: "2029-12-31T15:00:00Z",
+			dueAt:   "2030-01-02T14:59:59Z",
+		},
+		{
+			name:    "異常系: 不正なタイムゾーン: 400",
+			payload: `{"title": "Schedule", "status": "todo", "priority": "P2", "due_at": "2030-01-02", "timezone": "Mars/Olympus"}`,
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "異常系: 開始日時より前の期限: 400",
+			payload: `{"title": "Schedule", "status": "todo", "priority": "P2", "start_at": "2030-01-02", "due_at": "2030-01-01T00:00:00Z"}`,
+			status:  http.StatusBadRequest,
+		},
+	}
+	for _, c := range createCases {
+		t.Run(caseNameHelper(t, c.name, "POST", "/todo"), func(t *testing.T) {
+			req, err := http.NewRequest("POST", ts.URL+"/todo", bytes.NewBuffer([]byte(c.payload)))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			req.Header.Set("Authorization", auth)
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != c.status {
+				t.Fatalf("Expected status code %v, got %v", c.status, res.StatusCode)
+			}
+			if c.status != http.StatusCreated {
+				return
+			}
+			var resData handler.Todo
+			json.NewDecoder(res.Body).Decode(&resData)
+			if resData.StartAt == nil || resData.StartAt.UTC().Format(time.RFC3339) != c.startAt {
+				t.Fatalf("StartAt: want %v, got %v", c.startAt, resData.StartAt)
+			}
+			if resData.DueAt == nil || resData.DueAt.UTC().Format(time.RFC3339) != c.dueAt {
+				t.Fatalf("DueAt: want %v, got %v", c.dueAt, resData.DueAt)
+			}
+			repos.Todos.DeleteItem(ctx, userID, uint(resData.ID))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+	"github.com/Z-me/practice-todo-api/api/model"
+)
+
+func TestSearchTodo(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
+	longDetails := strings.Repeat("lorem ipsum ", 30) + "remember the quarterly budget " + strings.Repeat("dolor sit ", 30)
+	seeds := []model.Payload{
+		{Title: "Budget review", Status: "todo", Details: "check the budget numbers", Priority: "P1"},
+		{Title: "Long notes", Status: "todo", Details: longDetails, Priority: "P2"},
+		{Title: "Unrelated", Status: "todo", Details: "nothing to see", Priority: "P3"},
+	}
+	ids := []int{}
+	for _, p := range seeds {
+		item, err := repos.Todos.AddNewTodo(ctx, userID, p)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		ids = append(ids, int(item.ID))
+	}
+	auth := getAuth()
+
+	cases := []struct {
+		name     string
+		url      string
+		status   int
+		expected []int
+	}{
+		{
+			name:     "正常系: TitleとDetailsの検索",
+			url:      "/todo/search?q=budget",
+			status:   http.StatusOK,
+			expected: []int{ids[0], ids[1]},
+		},
+		{
+			name:     "正常系: 複数の検索語",
+			url:      "/todo/search?q=quarterly+budget",
+			status:   http.StatusOK,
+			expected: []int{ids[1]},
+		},
+		{
+			name:     "正常系: 件数の指定",
+			url:      "/todo/search?q=budget&limit=1",
+			status:   http.StatusOK,
+			expected: []int{ids[0]},
+		},
+		{
+			name:     "正常系: 一致なし",
+			url:      "/todo/search?q=missing",
+			status:   http.StatusOK,
+			expected: []int{},
+		},
+		{
+			name:   "異常系: 検索語なし",
+			url:    "/todo/search?q=",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(caseNameHelper(t, c.name, "GET", c.url), func(t *testing.T) {
+			req, err := http.NewRequest("GET", ts.URL+c.url, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			req.Header.Set("Authorization", auth)
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != c.status {
+				t.Fatalf("Expected status code %v, got %v", c.status, res.StatusCode)
+			}
+			if c.expected == nil {
+				return
+			}
+			var resData []handler.SearchResult
+			json.NewDecoder(res.Body).Decode(&resData)
+			if len(resData) != len(c.expected) {
+				t.Fatalf("Length: want %v items, got %v", len(c.expected), len(resData))
+			}
+			for i, id := range c.expected {
+				if resData[i].ID != id {
+					t.Fatalf("Contents: want %v, got %v", c.expected, resData)
+				}
+				if !strings.Contains(resData[i].TitleSnippet+resData[i].DetailsSnippet, model.HighlightStart) {
+					t.Fatalf("Snippet: want highlighted, got %v / %v", resData[i].TitleSnippet, resData[i].DetailsSnippet)
+				}
+			}
+		})
+	}
+
+	t.Run("長いDetailsの抜粋", func(t *testing.T) {
+		results, err := repos.Todos.SearchTodo(ctx, userID, "quarterly", 10)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(results) != 1 || len(results[0].DetailsSnippet) >= len(longDetails) {
+			t.Fatalf("Snippet: want shortened details, got %v", results)
+		}
+		if !strings.Contains(results[0].DetailsSnippet, model.HighlightStart+"quarterly"+model.HighlightStop) {
+			t.Fatalf("Snippet: want highlighted term, got %v", results[0].DetailsSnippet)
+		}
+	})
+
+	t.Run(caseNameHelper(t, "正常系: 抜粋のHTMLエスケープ", "GET", "/todo/search?q=escapeme"), func(t *testing.T) {
+		item, err := repos.Todos.AddNewTodo(ctx, userID, model.Payload{
+			Title:    `escapeme <img src=x onerror=alert(1)>`,
+			Status:   "todo",
+			Details:  `escapeme & "quoted" <script>alert(1)</script>`,
+			Priority: "P2",
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		t.Cleanup(func() {
+			repos.Todos.DeleteItem(ctx, userID, item.ID)
+		})
+		status, body := sendRequest(t, ts, "GET", "/todo/search?q=escapeme", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData []handler.SearchResult
+		json.Unmarshal(body, &resData)
+		if len(resData) != 1 {
+			t.Fatalf("Length: want 1 item, got %s", body)
+		}
+		snippets := resData[0].TitleSnippet + resData[0].DetailsSnippet
+		if strings.Contains(snippets, "<img") || strings.Contains(snippets, "<script") || !strings.Contains(snippets, "&lt;img") {
+			t.Fatalf("Snippet: want escaped, got %v / %v", resData[0].TitleSnippet, resData[0].DetailsSnippet)
+		}
+		if !strings.Contains(resData[0].TitleSnippet, model.HighlightStart+"escapeme"+model.HighlightStop) {
+			t.Fatalf("Snippet: want highlighted term, got %v", resData[0].TitleSnippet)
+		}
+	})
+
+	t.Run(caseNameHelper(t, "正常系: 進捗及び依存関係の読み込み", "GET", "/todo/search?q=detailsme"), func(t *testing.T) {
+		parent, err := repos.Todos.AddNewTodo(ctx, userID, model.Payload{Title: "detailsme parent", Status: "todo", Priority: "P2"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		child, err := repos.Todos.AddNewTodo(ctx, userID, model.Payload{Title: "Child", Status: "done", Priority: "P2", ParentID: &parent.ID})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		blocker, err := repos.Todos.AddNewTodo(ctx, userID, model.Payload{Title: "Blocker", Status: "todo", Priority: "P2"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		t.Cleanup(func() {
+			for _, id := range []uint{child.ID, parent.ID, blocker.ID} {
+				repos.Todos.DeleteItem(ctx, userID, id)
+			}
+		})
+		if _, err := repos.Todos.AddDependency(ctx, userID, parent.ID, blocker.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		status, body := sendRequest(t, ts, "GET", "/todo/search?q=detailsme", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData []handler.SearchResult
+		json.Unmarshal(body, &resData)
+		if len(resData) != 1 || resData[0].Progress == nil || *resData[0].Progress != 100 ||
+			len(resData[0].BlockedBy) != 1 || resData[0].BlockedBy[0] != int(blocker.ID) {
+			t.Fatalf("Details: want progress and blocked_by, got %s", body)
+		}
+	})
+}
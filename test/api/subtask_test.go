@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestSubtasks(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (親子関係を変更するため専用のユーザーを利用する)
+	_, auth := newTestUser(t, repos, "subtask_test")
+
+	createTodo := func(t *testing.T, title string, parentID int) handler.Todo {
+		t.Helper()
+		payload := fmt.Sprintf(`{"title": %q, "status": "todo", "priority": "P2", "parent_id": %d}`, title, parentID)
+		status, body := sendRequest(t, ts, "POST", "/todo", auth, payload)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		return resData
+	}
+	getTodo := func(t *testing.T, id int) handler.Todo {
+		t.Helper()
+		_, body := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(id), auth, "")
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		return resData
+	}
+	progressOf := func(todo handler.Todo) string {
+		if todo.Progress == nil {
+			return "nil"
+		}
+		return strconv.Itoa(*todo.Progress)
+	}
+
+	// Note: parent ─┬ child1 ── grandchild
+	//               └ child2
+	parent := createTodo(t, "Parent", 0)
+	child1 := createTodo(t, "Child1", parent.ID)
+	child2 := createTodo(t, "Child2", parent.ID)
+	grandchild := createTodo(t, "Grandchild", child1.ID)
+
+	t.Run("正常系: 子のTodoリストと完了率", func(t *testing.T) {
+		status, body := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(parent.ID)+"/children", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var children []handler.Todo
+		json.Unmarshal(body, &children)
+		if len(children) != 2 || children[0].ID != child1.ID || children[1].ID != child2.ID {
+			t.Fatalf("Children: want [%v %v], got %s", child1.ID, child2.ID, body)
+		}
+		if got := progressOf(getTodo(t, parent.ID)); got != "0" {
+			t.Fatalf("Progress: want 0, got %v", got)
+		}
+		if got := progressOf(getTodo(t, child2.ID)); got != "nil" {
+			t.Fatalf("Progress: want nil, got %v", got)
+		}
+	})
+
+	t.Run("正常系: 入れ子のTodoリスト", func(t *testing.T) {
+		status, body := sendRequest(t, ts, "GET", "/todo?tree=true", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var nodes []handler.TodoNode
+		json.Unmarshal(body, &nodes)
+		if len(nodes) != 1 || len(nodes[0].Children) != 2 || len(nodes[0].Children[0].Children) != 1 {
+			t.Fatalf("Tree: unexpected %s", body)
+		}
+		if nodes[0].Children[0].Children[0].ID != grandchild.ID {
+			t.Fatalf("Grandchild: want %v, got %v", grandchild.ID, nodes[0].Children[0].Children[0].ID)
+		}
+	})
+
+	t.Run(caseNameHelper(t, "正常系: 入れ子のTodoリストのページ送り", "GET", "/todo?tree=true&limit=3"), func(t *testing.T) {
+		// Note: ページは入れ子にする前のTodoで分割し、親が前のページにある孫は次のページの最上位となる
+		var first []handler.TodoNode
+		next := getPage(t, ts, "/todo?tree=true&limit=3", auth, &first)
+		if len(first) != 1 || first[0].ID != parent.ID || len(first[0].Children) != 2 || len(first[0].Children[0].Children) != 0 || next == "" {
+			t.Fatalf("Unexpected first page: %+v, next = %v", first, next)
+		}
+		var second []handler.TodoNode
+		next = getPage(t, ts, next, auth, &second)
+		if len(second) != 1 || second[0].ID != grandchild.ID || next != "" {
+			t.Fatalf("Unexpected second page: %+v, next = %v", second, next)
+		}
+	})
+
+	invalidCases := []struct {
+		name     string
+		id       int
+		parentID int
+	}{
+		{"自身を親に指定", parent.ID, parent.ID},
+		{"子孫を親に指定", parent.ID, grandchild.ID},
+		{"存在しない親を指定", child2.ID, 99999},
+	}
+	for _, tc := range invalidCases {
+		url := "/todo/" + strconv.Itoa(tc.id)
+		t.Run(caseNameHelper(t, "異常系: "+tc.name, "PUT", url), func(t *testing.T) {
+			payload := fmt.Sprintf(`{"title": "Invalid", "status": "todo", "priority": "P2", "parent_id": %d}`, tc.parentID)
+			if status, body := sendRequest(t, ts, "PUT", url, auth, payload); status != http.StatusBadRequest {
+				t.Fatalf("Expected status code %v, got %v: %s", http.StatusBadRequest, status, body)
+			}
+		})
+	}
+
+	t.Run("正常系: 子の完了と完了率", func(t *testing.T) {
+		if status, body := sendRequest(t, ts, "PATCH", "/todo/"+strconv.Itoa(child2.ID)+"/status", auth, `{"status": "done"}`); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		if got := progressOf(getTodo(t, parent.ID)); got != "50" {
+			t.Fatalf("Progress: want 50, got %v", got)
+		}
+	})
+
+	t.Run("異常系: 未完了の子孫を持つTodoの完了", func(t *testing.T) {
+		status, body := sendRequest(t, ts, "PATCH", "/todo/"+strconv.Itoa(parent.ID)+"/status", auth, `{"status": "done"}`)
+		if status != http.StatusConflict {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusConflict, status, body)
+		}
+		var resData struct {
+			OpenChildren []int `json:"open_children"`
+		}
+		json.Unmarshal(body, &resData)
+		if fmt.Sprint(resData.OpenChildren) != fmt.Sprint([]int{child1.ID, grandchild.ID}) {
+			t.Fatalf("OpenChildren: want [%v %v], got %v", child1.ID, grandchild.ID, resData.OpenChildren)
+		}
+		if got := getTodo(t, parent.ID).Status; got != "todo" {
+			t.Fatalf("Status: want todo, got %v", got)
+		}
+	})
+
+	t.Run("正常系: 子孫と共に完了", func(t *testing.T) {
+		status, body := sendRequest(t, ts, "PATCH", "/todo/"+strconv.Itoa(parent.ID)+"/status", auth, `{"status": "done", "complete_children": true}`)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		for _, id := range []int{parent.ID, child1.ID, grandchild.ID} {
+			if got := getTodo(t, id); got.Status != "done" || got.CompletedAt == nil {
+				t.Fatalf("Todo %v: want done, got %v", id, got.Status)
+			}
+		}
+		if got := progressOf(getTodo(t, parent.ID)); got != "100" {
+			t.Fatalf("Progress: want 100, got %v", got)
+		}
+	})
+
+	t.Run("正常系: 親の削除", func(t *testing.T) {
+		if status, _ := sendRequest(t, ts, "DELETE", "/todo/"+strconv.Itoa(child1.ID), auth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+		if got := getTodo(t, grandchild.ID); got.ID != grandchild.ID || got.ParentID != nil {
+			t.Fatalf("Grandchild: want kept without parent, got %+v", got)
+		}
+	})
+}
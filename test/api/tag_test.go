@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestTags(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (タグを変更するため専用のユーザーを利用する)
+	_, auth := newTestUser(t, repos, "tag_test")
+
+	ids := []int{}
+	for _, tags := range []string{`["work", "urgent", "work"]`, `["work"]`, `["home"]`} {
+		status, body := sendRequest(t, ts, "POST", "/todo", auth, `{"title": "Tagged", "status": "todo", "priority": "P2", "tags": `+tags+`}`)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		ids = append(ids, resData.ID)
+	}
+	tagIDs := map[string]int{}
+	getTags := func(t *testing.T) []handler.Tag {
+		t.Helper()
+		status, body := sendRequest(t, ts, "GET", "/tags", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+		var tags []handler.Tag
+		json.Unmarshal(body, &tags)
+		for _, tag := range tags {
+			tagIDs[tag.Name] = tag.ID
+		}
+		return tags
+	}
+	getTodoTags := func(t *testing.T, id int) []string {
+		t.Helper()
+		_, body := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(id), auth, "")
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		return resData.Tags
+	}
+	equal := func(a, b []string) bool {
+		ja, _ := json.Marshal(a)
+		jb, _ := json.Marshal(b)
+		return string(ja) == string(jb)
+	}
+
+	t.Run("正常系: Todoのタグ", func(t *testing.T) {
+		if got := getTodoTags(t, ids[0]); !equal(got, []string{"urgent", "work"}) {
+			t.Fatalf("Tags: want [urgent work], got %v", got)
+		}
+	})
+
+	t.Run("正常系: タグの作成と件数", func(t *testing.T) {
+		if status, _ := sendRequest(t, ts, "POST", "/tags", auth, `{"name": "work"}`); status != http.StatusConflict {
+			t.Fatalf("Expected status code %v, got %v", http.StatusConflict, status)
+		}
+		if status, _ := sendRequest(t, ts, "POST", "/tags", auth, `{"name": "later"}`); status != http.StatusCreated {
+			t.Fatalf("Expected status code %v, got %v", http.StatusCreated, status)
+		}
+		expected := map[string]int{"home": 1, "later": 0, "urgent": 1, "work": 2}
+		tags := getTags(t)
+		if len(tags) != len(expected) {
+			t.Fatalf("Length: want %v, got %v", len(expected), len(tags))
+		}
+		for _, tag := range tags {
+			if tag.Count == nil || *tag.Count != expected[tag.Name] {
+				t.Fatalf("Count of %v: want %v, got %v", tag.Name, expected[tag.Name], tag.Count)
+			}
+		}
+	})
+
+	filterCases := []struct {
+		name     string
+		url      string
+		expected []int
+	}{
+		{name: "正常系: いずれかのタグ", url: "/todo?tag=work&tag=home", expected: []int{ids[0], ids[1], ids[2]}},
+		{name: "正常系: 全てのタグ", url: "/todo?tag=work,urgent&tag_match=all", expected: []int{ids[0]}},
+		{name: "正常系: 重複したタグの指定", url: "/todo?tag=home&tag=home&tag_match=all", expected: []int{ids[2]}},
+	}
+	for _, c := range filterCases {
+		t.Run(caseNameHelper(t, c.name, "GET", c.url), func(t *testing.T) {
+			status, body := sendRequest(t, ts, "GET", c.url, auth, "")
+			if status != http.StatusOK {
+				t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+			}
+			var resData []handler.Todo
+			json.Unmarshal(body, &resData)
+			if len(resData) != len(c.expected) {
+				t.Fatalf("Length: want %v, got %v", c.expected, resData)
+			}
+			for i, id := range c.expected {
+				if resData[i].ID != id {
+					t.Fatalf("Contents: want %v, got %v", c.expected, resData)
+				}
+			}
+		})
+	}
+
+	t.Run("正常系: タグ名の変更", func(t *testing.T) {
+		url := "/tags/" + strconv.Itoa(tagIDs["work"])
+		if status, _ := sendRequest(t, ts, "PATCH", url, auth, `{"name": "home"}`); status != http.StatusConflict {
+			t.Fatalf("Expected status code %v, got %v", http.StatusConflict, status)
+		}
+		if status, _ := sendRequest(t, ts, "PATCH", url, auth, `{"name": "office"}`); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+		if got := getTodoTags(t, ids[1]); !equal(got, []string{"office"}) {
+			t.Fatalf("Tags: want [office], got %v", got)
+		}
+	})
+
+	t.Run("正常系: タグを省略した更新", func(t *testing.T) {
+		url := "/todo/" + strconv.Itoa(ids[0])
+		if status, _ := sendRequest(t, ts, "PUT", url, auth, `{"title": "Retitled", "status": "todo", "priority": "P2"}`); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+		if got := getTodoTags(t, ids[0]); !equal(got, []string{"office", "urgent"}) {
+			t.Fatalf("Tags: want [office urgent], got %v", got)
+		}
+	})
+
+	t.Run("正常系: タグの削除", func(t *testing.T) {
+		url := "/tags/" + strconv.Itoa(tagIDs["urgent"])
+		if status, _ := sendRequest(t, ts, "DELETE", url, auth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+		}
+		if status, _ := sendRequest(t, ts, "DELETE", url, auth, ""); status != http.StatusNotFound {
+			t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, status)
+		}
+		if got := getTodoTags(t, ids[0]); !equal(got, []string{"office"}) {
+			t.Fatalf("Tags: want [office], got %v", got)
+		}
+	})
+
+	t.Run("異常系: 他のユーザーのタグ", func(t *testing.T) {
+		status, body := sendRequest(t, ts, "GET", "/tags", getAuth(), "")
+		if status != http.StatusOK || string(body) != "[]" {
+			t.Fatalf("Expected no tags, got %v: %s", status, body)
+		}
+		url := "/tags/" + strconv.Itoa(tagIDs["home"])
+		if status, _ := sendRequest(t, ts, "DELETE", url, getAuth(), ""); status != http.StatusNotFound {
+			t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, status)
+		}
+	})
+}
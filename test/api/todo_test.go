@@ -2,24 +2,158 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io"
 
 	"strconv"
 	"time"
 
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"regexp"
 	"testing"
 
 	"github.com/Z-me/practice-todo-api/api"
 	"github.com/Z-me/practice-todo-api/api/handler"
 	"github.com/Z-me/practice-todo-api/api/model"
-	"github.com/Z-me/practice-todo-api/lib/db"
-	"github.com/Z-me/practice-todo-api/lib/util"
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/repository"
 )
 
+// testConfig はテスト用の設定を返却する
+// TODO_TEST_DB_DRIVER 及び TODO_TEST_DB_DSN でテスト用DBの接続先を変更できる
+func testConfig(t *testing.T) config.Config {
+	t.Helper()
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	cfg.DB.Driver = config.DriverSQLite
+	cfg.DB.DSN = ":memory:"
+	if driver := os.Getenv("TODO_TEST_DB_DRIVER"); driver != "" {
+		cfg.DB.Driver = driver
+	}
+	if dsn := os.Getenv("TODO_TEST_DB_DSN"); dsn != "" {
+		cfg.DB.DSN = dsn
+	}
+	return cfg
+}
+
+// openTestRepos はテスト用のRepositoryを生成してテストユーザーを登録し、テスト終了時に切断する
+func openTestRepos(t *testing.T, cfg config.Config) repository.Repositories {
+	t.Helper()
+	repos, err := repository.Open(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() {
+		repos.Close()
+	})
+	ctx := context.Background()
+	if _, err := repos.Users.GetUserByName(ctx, "test"); errors.Is(err, repository.ErrNotFound) {
+		_, err = repos.Users.AddNewUser(ctx, model.UserPayload{Name: "test", Password: "password"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	return repos
+}
+
+// newTestUser はテスト専用のユーザーを登録し、ユーザーとBasic認証のヘッダーを返却する
+// ユーザー及びそのデータはテスト終了時に削除する
+func newTestUser(t *testing.T, repos repository.Repositories, name string) (model.User, string) {
+	t.Helper()
+	ctx := context.Background()
+	user, err := repos.Users.AddNewUser(ctx, model.UserPayload{Name: name, Password: "passw0rd123"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() {
+		repos.Users.DeleteUser(ctx, user.ID)
+	})
+	return user, basicAuth(name, "passw0rd123")
+}
+
+func basicAuth(name, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(name+":"+password))
+}
+
+// sendRequest はJSONのPayloadでリクエストを送信し、ステータスコードとレスポンスのBodyを返却する
+func sendRequest(t *testing.T, ts *httptest.Server, method, url, auth, payload string) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+url, bytes.NewBuffer([]byte(payload)))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return res.StatusCode, body
+}
+
+var nextLinkPattern = regexp.MustCompile(`^<([^>]+)>; rel="next"$`)
+
+// getTodoPage はTodoリストの1ページを取得し、次のページのURLと共に返却する
+func getTodoPage(t *testing.T, ts *httptest.Server, url string) ([]handler.Todo, string) {
+	t.Helper()
+	var resData []handler.Todo
+	next := getPage(t, ts, url, getAuth(), &resData)
+	return resData, next
+}
+
+// getPage はリストの1ページを取得してresDataに読み込み、次のページのURLを返却する
+func getPage(t *testing.T, ts *httptest.Server, url, auth string, resData interface{}) string {
+	t.Helper()
+	req, err := http.NewRequest("GET", ts.URL+url, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Set("Authorization", auth)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	json.NewDecoder(res.Body).Decode(resData)
+
+	next := ""
+	if link := res.Header.Get("Link"); link != "" {
+		m := nextLinkPattern.FindStringSubmatch(link)
+		if m == nil {
+			t.Fatalf("Unexpected Link header: %v", link)
+		}
+		next = m[1]
+	}
+	return next
+}
+
 func getAuth() string {
-	return "Basic test:password"
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte("test:password"))
+}
+
+func getTestUserID(t *testing.T, repos repository.Repositories) uint {
+	t.Helper()
+	user, err := repos.Users.GetUserByName(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return user.ID
 }
 
 func caseNameHelper(t *testing.T, name string, method string, url string) string {
@@ -41,7 +175,7 @@ func compareTodoList(target model.TodoList, expect []handler.Todo) bool {
 		if v.Details != target[i].Details {
 			return false
 		}
-		if v.Priority != target[i].Priority {
+		if v.Priority != string(target[i].Priority) {
 			return false
 		}
 	}
@@ -50,20 +184,15 @@ func compareTodoList(target model.TodoList, expect []handler.Todo) bool {
 
 func TestGetTodoList(t *testing.T) {
 	// Note: Start test Server
-	ts := httptest.NewServer(api.Router())
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
 	defer ts.Close()
 
-	// Note: Start Connect DB
-	util.UseTestBD()
-	err := util.ConnectDB()
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
-	defer util.DisconnectDB()
-
 	// Note: 事前処理
-	dbObj := util.GetDbObj()
-	expected, err := db.GetTodoList(dbObj)
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
+	expected, err := repos.Todos.GetTodoList(ctx, userID, model.TodoFilter{}, model.TodoPage{})
 	auth := getAuth()
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
@@ -144,32 +273,32 @@ func TestGetTodoList(t *testing.T) {
 
 func TestGetTodoItem(t *testing.T) {
 	// Note: Start test Server
-	ts := httptest.NewServer(api.Router())
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
 	defer ts.Close()
 
-	// Note: Start Connect DB
-	util.UseTestBD()
-	err := util.ConnectDB()
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
-	defer util.DisconnectDB()
-
 	// Note: 事前処理
 	target := model.Payload{
 		Title:    "Test TODO",
-		Status:   "Done",
+		Status:   "done",
 		Details:  "test_todo",
 		Priority: "P2",
 	}
 	auth := getAuth()
-	dbObj := util.GetDbObj()
-	res, err := db.AddNewTodo(dbObj, target)
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
+	res, err := repos.Todos.AddNewTodo(ctx, userID, target)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 	nextID := res.ID
 	createdAt := res.CreatedAt
+	other, err := repos.Todos.AddNewTodo(ctx, userID+1, target)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	otherID := other.ID
 
 	cases := []struct {
 		name     string
@@ -190,7 +319,7 @@ func TestGetTodoItem(t *testing.T) {
 			expected: model.Todo{
 				ID:       nextID,
 				Title:    "Test TODO",
-				Status:   "Done",
+				Status:   "done",
 				Details:  "test_todo",
 				Priority: "P2",
 			},
@@ -213,6 +342,15 @@ func TestGetTodoItem(t *testing.T) {
 			isError:  true,
 			expected: model.Todo{},
 		},
+		{
+			name:     "異常系: 他ユーザーのItem取得: 404",
+			url:      "/todo/" + strconv.Itoa(int(otherID)),
+			method:   "GET",
+			auth:     true,
+			status:   http.StatusNotFound,
+			isError:  true,
+			expected: model.Todo{},
+		},
 		{
 			name:     "異常系: Item取得: 404",
 			url:      "/todo/" + strconv.Itoa(int(nextID)),
@@ -260,7 +398,7 @@ func TestGetTodoItem(t *testing.T) {
 				if c.expected.Details != resData.Details {
 					t.Fatalf("Details: want %v, resData = %v", c.expected.Details, resData.Details)
 				}
-				if c.expected.Priority != resData.Priority {
+				if string(c.expected.Priority) != resData.Priority {
 					t.Fatalf("Priority: want %v, resData = %v", c.expected.Priority, resData.Priority)
 				}
 				if !resData.CreatedAt.Equal(createdAt) {
@@ -270,33 +408,22 @@ func TestGetTodoItem(t *testing.T) {
 		})
 	}
 	// Note: 事後削除処理
-	err = util.ConnectDB()
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
-	defer util.DisconnectDB()
-
-	db.DeleteItem(dbObj, nextID)
+	repos.Todos.DeleteItem(ctx, userID, nextID)
+	repos.Todos.DeleteItem(ctx, userID+1, otherID)
 }
 
 func TestCreateItem(t *testing.T) {
 	// Note: Start test Server
-	ts := httptest.NewServer(api.Router())
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
 	defer ts.Close()
 
-	// Note: Start Connect DB
-	util.UseTestBD()
-	err := util.ConnectDB()
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
-	defer util.DisconnectDB()
-
 	// Note: each values
 	now := time.Now()
 	auth := getAuth()
-	dbObj := util.GetDbObj()
-	nextID := db.GetNextID(dbObj)
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
 	cases := []struct {
 		name        string
 		url         string
@@ -315,11 +442,10 @@ func TestCreateItem(t *testing.T) {
 			auth:    true,
 			status:  http.StatusCreated,
 			isError: false,
-			payload: `{"title": "Test TODO", "status": "Done", "details": "test_todo", "priority": "P0"}`,
+			payload: `{"title": "Test TODO", "status": "done", "details": "test_todo", "priority": "P0"}`,
 			expected: model.Todo{
-				ID:       nextID,
 				Title:    "Test TODO",
-				Status:   "Done",
+				Status:   "done",
 				Details:  "test_todo",
 				Priority: "P0",
 			},
@@ -336,6 +462,33 @@ func TestCreateItem(t *testing.T) {
 			expected:    model.Todo{},
 			need2Delete: false,
 		},
+		{
+			name:    "正常系: 新規追加: Priorityの大文字小文字",
+			url:     "/todo",
+			method:  "POST",
+			auth:    true,
+			status:  http.StatusCreated,
+			isError: false,
+			payload: `{"title": "Test TODO", "status": "todo", "details": "test_todo", "priority": "p3"}`,
+			expected: model.Todo{
+				Title:    "Test TODO",
+				Status:   "todo",
+				Details:  "test_todo",
+				Priority: "P3",
+			},
+			need2Delete: true,
+		},
+		{
+			name:        "異常系: 新規追加: 不正なPriority: 400",
+			url:         "/todo",
+			method:      "POST",
+			auth:        true,
+			status:      http.StatusBadRequest,
+			isError:     true,
+			payload:     `{"title": "Test TODO", "status": "todo", "details": "test_todo", "priority": "very very important"}`,
+			expected:    model.Todo{},
+			need2Delete: false,
+		},
 		{
 			name:        "異常系: 新規追加: 401",
 			url:         "/todo",
@@ -394,7 +547,7 @@ func TestCreateItem(t *testing.T) {
 				if c.expected.Details != resData.Details {
 					t.Fatalf("Details: want %v, resData = %v", c.expected.Details, resData.Details)
 				}
-				if c.expected.Priority != resData.Priority {
+				if string(c.expected.Priority) != resData.Priority {
 					t.Fatalf("Priority: want %v, resData = %v", c.expected.Priority, resData.Priority)
 				}
 				if !resData.CreatedAt.After(now) {
@@ -407,13 +560,7 @@ func TestCreateItem(t *testing.T) {
 
 			// 終了処理
 			if c.need2Delete {
-				err = util.ConnectDB()
-				if err != nil {
-					t.Fatalf("Expected no error, got %v", err)
-				}
-				defer util.DisconnectDB()
-
-				_, err := db.DeleteItem(dbObj, uint(resData.ID))
+				_, err := repos.Todos.DeleteItem(ctx, userID, uint(resData.ID))
 				if err != nil {
 					t.Fatalf("Expected no error, got %v", err)
 				}
@@ -424,32 +571,32 @@ func TestCreateItem(t *testing.T) {
 
 func TestUpdateItem(t *testing.T) {
 	// Note: Start test Server
-	ts := httptest.NewServer(api.Router())
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
 	defer ts.Close()
 
-	// Note: Start Connect DB
-	util.UseTestBD()
-	err := util.ConnectDB()
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
-	defer util.DisconnectDB()
-
 	// Note: 事前処理
 	auth := getAuth()
-	dbObj := util.GetDbObj()
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
 	target := model.Payload{
 		Title:    "Test TODO",
-		Status:   "Done",
+		Status:   "done",
 		Details:  "test_todo",
 		Priority: "P0",
 	}
-	res, err := db.AddNewTodo(dbObj, target)
+	res, err := repos.Todos.AddNewTodo(ctx, userID, target)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 	nextID := res.ID
 	createdAt := res.CreatedAt
+	other, err := repos.Todos.AddNewTodo(ctx, userID+1, target)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	otherID := other.ID
 
 	cases := []struct {
 		name     string
@@ -468,11 +615,11 @@ func TestUpdateItem(t *testing.T) {
 			auth:    true,
 			status:  http.StatusOK,
 			isError: false,
-			payload: `{"title": "Changed TODO", "status": "Done", "details": "changed_todo", "priority": "P0"}`,
+			payload: `{"title": "Changed TODO", "status": "done", "details": "changed_todo", "priority": "P0"}`,
 			expected: model.Todo{
 				ID:       nextID,
 				Title:    "Changed TODO",
-				Status:   "Done",
+				Status:   "done",
 				Details:  "changed_todo",
 				Priority: "P0",
 			},
@@ -497,6 +644,16 @@ func TestUpdateItem(t *testing.T) {
 			payload:  "",
 			expected: model.Todo{},
 		},
+		{
+			name:     "異常系: 他ユーザーのItem更新: 404",
+			url:      "/todo/" + strconv.Itoa(int(otherID)),
+			method:   "PUT",
+			auth:     true,
+			status:   http.StatusNotFound,
+			isError:  true,
+			payload:  `{"title": "Changed TODO", "status": "todo", "details": "changed_todo", "priority": "P0"}`,
+			expected: model.Todo{},
+		},
 		{
 			name:     "異常系: 更新: 404",
 			url:      "/todo/" + strconv.Itoa(int(nextID)),
@@ -504,7 +661,7 @@ func TestUpdateItem(t *testing.T) {
 			auth:     true,
 			status:   http.StatusNotFound,
 			isError:  true,
-			payload:  `{"title": "Changed TODO", "status": "Done", "details": "changed_todo", "priority": "P0"}`,
+			payload:  `{"title": "Changed TODO", "status": "done", "details": "changed_todo", "priority": "P0"}`,
 			expected: model.Todo{},
 		},
 	}
@@ -545,7 +702,7 @@ func TestUpdateItem(t *testing.T) {
 				if c.expected.Details != resData.Details {
 					t.Fatalf("Details: want %v, resData = %v", c.expected.Details, resData.Details)
 				}
-				if c.expected.Priority != resData.Priority {
+				if string(c.expected.Priority) != resData.Priority {
 					t.Fatalf("Priority: want %v, resData = %v", c.expected.Priority, resData.Priority)
 				}
 				if !resData.CreatedAt.Equal(createdAt) {
@@ -557,44 +714,43 @@ func TestUpdateItem(t *testing.T) {
 			}
 		})
 	}
-	// Note: 事後削除処理
-	err = util.ConnectDB()
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
+	// Note: 他ユーザーのItemが更新されていないことを確認する
+	if got, err := repos.Todos.GetTodoItemByID(ctx, userID+1, otherID); err != nil || got.Title != target.Title || got.Status != target.Status {
+		t.Fatalf("Other user's item: want unchanged, got %+v, %v", got, err)
 	}
-	defer util.DisconnectDB()
-
-	db.DeleteItem(dbObj, nextID)
+	// Note: 事後削除処理
+	repos.Todos.DeleteItem(ctx, userID, nextID)
+	repos.Todos.DeleteItem(ctx, userID+1, otherID)
 }
 
 func TestUpdateItemState(t *testing.T) {
 	// Note: Start test Server
-	ts := httptest.NewServer(api.Router())
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
 	defer ts.Close()
 
-	// Note: Start Connect DB
-	util.UseTestBD()
-	err := util.ConnectDB()
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
-	defer util.DisconnectDB()
-
 	// Note: 事前処理
 	target := model.Payload{
 		Title:    "Test TODO",
-		Status:   "Done",
+		Status:   "done",
 		Details:  "test_todo",
 		Priority: "P0",
 	}
-	dbObj := util.GetDbObj()
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
 	auth := getAuth()
-	res, err := db.AddNewTodo(dbObj, target)
+	res, err := repos.Todos.AddNewTodo(ctx, userID, target)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 	nextID := res.ID
 	createdAt := res.CreatedAt
+	other, err := repos.Todos.AddNewTodo(ctx, userID+1, target)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	otherID := other.ID
 
 	cases := []struct {
 		name     string
@@ -613,11 +769,11 @@ func TestUpdateItemState(t *testing.T) {
 			auth:    true,
 			status:  http.StatusOK,
 			isError: false,
-			payload: `{"title": "Changed TODO", "status": "Done", "details": "changed_todo", "priority": "P0"}`,
+			payload: `{"title": "Changed TODO", "status": "done", "details": "changed_todo", "priority": "P0"}`,
 			expected: model.Todo{
 				ID:       nextID,
 				Title:    "Changed TODO",
-				Status:   "Done",
+				Status:   "done",
 				Details:  "changed_todo",
 				Priority: "P0",
 			},
@@ -639,7 +795,17 @@ func TestUpdateItemState(t *testing.T) {
 			auth:     false,
 			status:   http.StatusUnauthorized,
 			isError:  true,
-			payload:  `{"title": "Changed TODO", "status": "Done", "details": "changed_todo", "priority": "P0"}`,
+			payload:  `{"title": "Changed TODO", "status": "done", "details": "changed_todo", "priority": "P0"}`,
+			expected: model.Todo{},
+		},
+		{
+			name:     "異常系: 他ユーザーのItemのStatus更新: 404",
+			url:      "/todo/" + strconv.Itoa(int(otherID)) + "/status",
+			method:   "PATCH",
+			auth:     true,
+			status:   http.StatusNotFound,
+			isError:  true,
+			payload:  `{"status": "todo"}`,
 			expected: model.Todo{},
 		},
 		{
@@ -649,7 +815,7 @@ func TestUpdateItemState(t *testing.T) {
 			auth:     true,
 			status:   http.StatusNotFound,
 			isError:  true,
-			payload:  `{"title": "Changed TODO", "status": "Done", "details": "changed_todo", "priority": "P0"}`,
+			payload:  `{"title": "Changed TODO", "status": "done", "details": "changed_todo", "priority": "P0"}`,
 			expected: model.Todo{},
 		},
 	}
@@ -692,7 +858,7 @@ func TestUpdateItemState(t *testing.T) {
 				if c.expected.Details != resData.Details {
 					t.Fatalf("Details: want %v, resData = %v", c.expected.Details, resData.Details)
 				}
-				if c.expected.Priority != resData.Priority {
+				if string(c.expected.Priority) != resData.Priority {
 					t.Fatalf("Priority: want %v, resData = %v", c.expected.Priority, resData.Priority)
 				}
 				if !resData.CreatedAt.Equal(createdAt) {
@@ -704,43 +870,42 @@ func TestUpdateItemState(t *testing.T) {
 			}
 		})
 	}
-	// Note: 事後削除処理
-	err = util.ConnectDB()
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
+	// Note: 他ユーザーのItemが更新されていないことを確認する
+	if got, err := repos.Todos.GetTodoItemByID(ctx, userID+1, otherID); err != nil || got.Title != target.Title || got.Status != target.Status {
+		t.Fatalf("Other user's item: want unchanged, got %+v, %v", got, err)
 	}
-	defer util.DisconnectDB()
-
-	db.DeleteItem(dbObj, nextID)
+	// Note: 事後削除処理
+	repos.Todos.DeleteItem(ctx, userID, nextID)
+	repos.Todos.DeleteItem(ctx, userID+1, otherID)
 }
 
 func TestDeleteItemState(t *testing.T) {
 	// Note: Start test Server
-	ts := httptest.NewServer(api.Router())
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
 	defer ts.Close()
 
-	// Note: Start Connect DB
-	util.UseTestBD()
-	err := util.ConnectDB()
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
-	defer util.DisconnectDB()
-
 	// Note: 事前処理
 	target := model.Payload{
 		Title:    "Test TODO",
-		Status:   "Done",
+		Status:   "done",
 		Details:  "test_todo",
 		Priority: "P0",
 	}
-	dbObj := util.GetDbObj()
+	userID := getTestUserID(t, repos)
+	ctx := context.Background()
 	auth := getAuth()
-	res, err := db.AddNewTodo(dbObj, target)
+	res, err := repos.Todos.AddNewTodo(ctx, userID, target)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 	nextID := res.ID
+	other, err := repos.Todos.AddNewTodo(ctx, userID+1, target)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	otherID := other.ID
 
 	cases := []struct {
 		name     string
@@ -761,7 +926,7 @@ func TestDeleteItemState(t *testing.T) {
 			expected: model.Todo{
 				ID:       nextID,
 				Title:    "Test TODO",
-				Status:   "Done",
+				Status:   "done",
 				Details:  "test_todo",
 				Priority: "P0",
 			},
@@ -775,6 +940,15 @@ func TestDeleteItemState(t *testing.T) {
 			isError:  true,
 			expected: model.Todo{},
 		},
+		{
+			name:     "異常系: 他ユーザーのItem削除: 404",
+			url:      "/todo/" + strconv.Itoa(int(otherID)),
+			method:   "DELETE",
+			auth:     true,
+			status:   http.StatusNotFound,
+			isError:  true,
+			expected: model.Todo{},
+		},
 		{
 			name:     "異常系: 更新: 401",
 			url:      "/todo/" + strconv.Itoa(int(nextID-1)),
@@ -822,10 +996,16 @@ func TestDeleteItemState(t *testing.T) {
 				if c.expected.Details != resData.Details {
 					t.Fatalf("Details: want %v, resData = %v", c.expected.Details, resData.Details)
 				}
-				if c.expected.Priority != resData.Priority {
+				if string(c.expected.Priority) != resData.Priority {
 					t.Fatalf("Priority: want %v, resData = %v", c.expected.Priority, resData.Priority)
 				}
 			}
 		})
 	}
+	// Note: 他ユーザーのItemが削除されていないことを確認する
+	if got, err := repos.Todos.GetTodoItemByID(ctx, userID+1, otherID); err != nil || got.Title != target.Title || got.Status != target.Status {
+		t.Fatalf("Other user's item: want unchanged, got %+v, %v", got, err)
+	}
+	// Note: 事後削除処理
+	repos.Todos.DeleteItem(ctx, userID+1, otherID)
 }
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestTrash(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (Todoを削除するため専用のユーザーを利用する)
+	_, auth := newTestUser(t, repos, "trash_test")
+
+	ids := map[string]int{}
+	for _, v := range []struct{ name, payload string }{
+		{"blocker", `{"title": "Blocker", "status": "todo", "priority": "P2", "tags": ["trash"]}`},
+		{"blocked", `{"title": "Blocked", "status": "todo", "priority": "P2", "tags": ["trash"]}`},
+		{"parent", `{"title": "Parent", "status": "todo", "priority": "P2"}`},
+	} {
+		status, body := sendRequest(t, ts, "POST", "/todo", auth, v.payload)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		ids[v.name] = resData.ID
+	}
+	status, body := sendRequest(t, ts, "POST", "/todo", auth, fmt.Sprintf(`{"title": "Child", "status": "todo", "priority": "P2", "parent_id": %d}`, ids["parent"]))
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+	}
+	var child handler.Todo
+	json.Unmarshal(body, &child)
+	blockedURL := "/todo/" + strconv.Itoa(ids["blocked"])
+	if status, body := sendRequest(t, ts, "POST", blockedURL+"/dependencies", auth, fmt.Sprintf(`{"blocker_id": %d}`, ids["blocker"])); status != http.StatusCreated {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+	}
+
+	getTodo := func(t *testing.T, url string) handler.Todo {
+		t.Helper()
+		status, body := sendRequest(t, ts, "GET", url, auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		return resData
+	}
+	getTrash := func(t *testing.T) []int {
+		t.Helper()
+		status, body := sendRequest(t, ts, "GET", "/trash", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData []handler.Todo
+		json.Unmarshal(body, &resData)
+		result := []int{}
+		for _, v := range resData {
+			if v.DeletedAt == nil {
+				t.Fatalf("DeletedAt: want not nil, got nil")
+			}
+			result = append(result, v.ID)
+		}
+		return result
+	}
+	tagCount := func(t *testing.T) int {
+		t.Helper()
+		_, body := sendRequest(t, ts, "GET", "/tags", auth, "")
+		var tags []handler.Tag
+		json.Unmarshal(body, &tags)
+		if len(tags) != 1 || tags[0].Count == nil {
+			t.Fatalf("Tags: unexpected %s", body)
+		}
+		return *tags[0].Count
+	}
+	blockerURL := "/todo/" + strconv.Itoa(ids["blocker"])
+
+	t.Run(caseNameHelper(t, "正常系: ゴミ箱への移動", "DELETE", "/todo/:id"), func(t *testing.T) {
+		status, body := sendRequest(t, ts, "DELETE", blockerURL, auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		if resData.DeletedAt == nil || resData.CreatedAt.IsZero() || fmt.Sprint(resData.Tags) != "[trash]" {
+			t.Fatalf("Deleted: unexpected %s", body)
+		}
+		for _, method := range []string{"GET", "PUT", "DELETE"} {
+			if status, _ := sendRequest(t, ts, method, blockerURL, auth, `{"title": "Blocker", "status": "todo", "priority": "P2"}`); status != http.StatusNotFound {
+				t.Fatalf("[%s] Expected status code %v, got %v", method, http.StatusNotFound, status)
+			}
+		}
+		if got := getTrash(t); fmt.Sprint(got) != fmt.Sprint([]int{ids["blocker"]}) {
+			t.Fatalf("Trash: want [%v], got %v", ids["blocker"], got)
+		}
+		if got := tagCount(t); got != 1 {
+			t.Fatalf("Tag count: want 1, got %v", got)
+		}
+		if got := getTodo(t, blockedURL).BlockedBy; len(got) != 0 {
+			t.Fatalf("BlockedBy: want [], got %v", got)
+		}
+	})
+
+	t.Run(caseNameHelper(t, "正常系: ゴミ箱から元に戻す", "POST", "/todo/:id/restore"), func(t *testing.T) {
+		status, body := sendRequest(t, ts, "POST", blockerURL+"/restore", auth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var resData handler.Todo
+		json.Unmarshal(body, &resData)
+		if resData.DeletedAt != nil || fmt.Sprint(resData.Blocking) != fmt.Sprint([]int{ids["blocked"]}) {
+			t.Fatalf("Restored: unexpected %s", body)
+		}
+		if got := getTrash(t); len(got) != 0 {
+			t.Fatalf("Trash: want [], got %v", got)
+		}
+		if got := tagCount(t); got != 2 {
+			t.Fatalf("Tag count: want 2, got %v", got)
+		}
+		if status, _ := sendRequest(t, ts, "POST", blockerURL+"/restore", auth, ""); status != http.StatusNotFound {
+			t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, status)
+		}
+	})
+
+	parentURL := "/todo/" + strconv.Itoa(ids["parent"])
+	t.Run(caseNameHelper(t, "正常系: 完全に削除", "DELETE", "/trash/:id"), func(t *testing.T) {
+		if status, body := sendRequest(t, ts, "DELETE", parentURL, auth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		if got := getTodo(t, "/todo/"+strconv.Itoa(child.ID)).ParentID; got != nil {
+			t.Fatalf("ParentID: want nil, got %v", *got)
+		}
+		trashURL := "/trash/" + strconv.Itoa(ids["parent"])
+		if status, _ := sendRequest(t, ts, "DELETE", "/trash/"+strconv.Itoa(ids["blocked"]), auth, ""); status != http.StatusNotFound {
+			t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, status)
+		}
+		if status, _ := sendRequest(t, ts, "DELETE", trashURL, getAuth(), ""); status != http.StatusNotFound {
+			t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, status)
+		}
+		if status, body := sendRequest(t, ts, "DELETE", trashURL, auth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		if got := getTrash(t); len(got) != 0 {
+			t.Fatalf("Trash: want [], got %v", got)
+		}
+		if status, _ := sendRequest(t, ts, "POST", parentURL+"/restore", auth, ""); status != http.StatusNotFound {
+			t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, status)
+		}
+	})
+
+	t.Run("正常系: 保持期間を過ぎたItemの削除", func(t *testing.T) {
+		if status, body := sendRequest(t, ts, "DELETE", blockerURL, auth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		if _, err := repos.Todos.PurgeTrash(context.Background(), time.Now().Add(-time.Hour)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := getTrash(t); len(got) != 1 {
+			t.Fatalf("Trash: want 1 item, got %v", got)
+		}
+		count, err := repos.Todos.PurgeTrash(context.Background(), time.Now().Add(time.Second))
+		if err != nil || count < 1 {
+			t.Fatalf("Expected purged items, got %v, %v", count, err)
+		}
+		if got := getTrash(t); len(got) != 0 {
+			t.Fatalf("Trash: want [], got %v", got)
+		}
+		if got := getTodo(t, blockedURL).BlockedBy; len(got) != 0 {
+			t.Fatalf("BlockedBy: want [], got %v", got)
+		}
+	})
+}
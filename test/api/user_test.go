@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/handler"
+)
+
+func TestUserAccount(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	ts := httptest.NewServer(api.Router(cfg, openTestRepos(t, cfg)))
+	defer ts.Close()
+
+	// Note: 各ステップは前のステップの結果に依存するため順番に実行する
+	cases := []struct {
+		name     string
+		url      string
+		method   string
+		auth     string
+		status   int
+		payload  string
+		expected string
+	}{
+		{
+			name:     "正常系: ユーザー登録",
+			url:      "/users",
+			method:   "POST",
+			status:   http.StatusCreated,
+			payload:  `{"name": "account_test", "password": "passw0rd123"}`,
+			expected: "account_test",
+		},
+		{
+			name:    "異常系: ユーザー登録: 名前重複: 409",
+			url:     "/users",
+			method:  "POST",
+			status:  http.StatusConflict,
+			payload: `{"name": "account_test", "password": "passw0rd123"}`,
+		},
+		{
+			name:    "異常系: ユーザー登録: 弱いパスワード: 400",
+			url:     "/users",
+			method:  "POST",
+			status:  http.StatusBadRequest,
+			payload: `{"name": "account_test_weak", "password": "short"}`,
+		},
+		{
+			name:     "正常系: ログインユーザー取得",
+			url:      "/me",
+			method:   "GET",
+			auth:     basicAuth("account_test", "passw0rd123"),
+			status:   http.StatusOK,
+			expected: "account_test",
+		},
+		{
+			name:    "異常系: パスワード変更: 現在のパスワード誤り: 400",
+			url:     "/me",
+			method:  "PATCH",
+			auth:    basicAuth("account_test", "passw0rd123"),
+			status:  http.StatusBadRequest,
+			payload: `{"password": "newpassw0rd", "current_password": "wrong"}`,
+		},
+		{
+			name:     "正常系: ユーザー情報更新",
+			url:      "/me",
+			method:   "PATCH",
+			auth:     basicAuth("account_test", "passw0rd123"),
+			status:   http.StatusOK,
+			payload:  `{"name": "account_test_renamed", "password": "newpassw0rd", "current_password": "passw0rd123"}`,
+			expected: "account_test_renamed",
+		},
+		{
+			name:     "正常系: ユーザー削除",
+			url:      "/me",
+			method:   "DELETE",
+			auth:     basicAuth("account_test_renamed", "newpassw0rd"),
+			status:   http.StatusOK,
+			expected: "account_test_renamed",
+		},
+		{
+			name:   "異常系: 削除済みユーザー: 401",
+			url:    "/me",
+			method: "GET",
+			auth:   basicAuth("account_test_renamed", "newpassw0rd"),
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(caseNameHelper(t, c.name, c.method, c.url), func(t *testing.T) {
+			client := &http.Client{}
+			req, err := http.NewRequest(c.method, ts.URL+c.url, bytes.NewBuffer([]byte(c.payload)))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if c.auth != "" {
+				req.Header.Set("Authorization", c.auth)
+			}
+
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != c.status {
+				t.Fatalf("Expected status code %v, got %v", c.status, res.StatusCode)
+			}
+			var resData handler.User
+			json.NewDecoder(res.Body).Decode(&resData)
+
+			if c.expected != "" && c.expected != resData.Name {
+				t.Fatalf("Name: want %v, resData = %v", c.expected, resData.Name)
+			}
+		})
+	}
+}
+
+func TestDeleteUserTransfer(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理
+	_, fromAuth := newTestUser(t, repos, "transfer_from")
+	_, toAuth := newTestUser(t, repos, "transfer_to")
+	status, body := sendRequest(t, ts, "POST", "/todo", fromAuth, `{"title": "Transferred", "status": "todo", "priority": "P2", "tags": ["work"]}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusCreated, status, body)
+	}
+	var transferred handler.Todo
+	json.Unmarshal(body, &transferred)
+
+	t.Run(caseNameHelper(t, "異常系: 他のユーザーへの引き継ぎ", "DELETE", "/me?transfer_to=transfer_to"), func(t *testing.T) {
+		if status, body := sendRequest(t, ts, "DELETE", "/me?transfer_to=transfer_to", fromAuth, ""); status != http.StatusBadRequest {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusBadRequest, status, body)
+		}
+		// Note: 削除を拒否したため、Todoは元のユーザーに残り、引き継ぎ先には渡らない
+		if status, body := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(transferred.ID), fromAuth, ""); status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		if status, body := sendRequest(t, ts, "GET", "/todo/"+strconv.Itoa(transferred.ID), toAuth, ""); status != http.StatusNotFound {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusNotFound, status, body)
+		}
+		status, body := sendRequest(t, ts, "GET", "/tags", toAuth, "")
+		if status != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, status, body)
+		}
+		var tags []handler.Tag
+		json.Unmarshal(body, &tags)
+		if len(tags) != 0 {
+			t.Fatalf("Tags: want none, got %s", body)
+		}
+	})
+}
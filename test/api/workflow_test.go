@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/api"
+	"github.com/Z-me/practice-todo-api/api/model"
+)
+
+func TestStatusWorkflow(t *testing.T) {
+	// Note: Start test Server
+	cfg := testConfig(t)
+	repos := openTestRepos(t, cfg)
+	ts := httptest.NewServer(api.Router(cfg, repos))
+	defer ts.Close()
+
+	// Note: 事前処理 (ワークフローを変更するため専用のユーザーを利用する)
+	user, auth := newTestUser(t, repos, "workflow_test")
+	item, err := repos.Todos.AddNewTodo(context.Background(), user.ID, model.Payload{Title: "Workflow", Status: "todo", Priority: "P1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	statusURL := "/todo/" + strconv.Itoa(int(item.ID)) + "/status"
+
+	// Note: 各ステップは前のステップの結果に依存するため順番に実行する
+	cases := []struct {
+		name      string
+		url       string
+		method    string
+		status    int
+		payload   string
+		expected  map[string]interface{}
+		started   bool
+		completed bool
+	}{
+		{
+			name:     "正常系: 着手",
+			url:      statusURL,
+			method:   "PATCH",
+			status:   http.StatusOK,
+			payload:  `{"status": "in_progress"}`,
+			expected: map[string]interface{}{"status": "in_progress"},
+			started:  true,
+		},
+		{
+			name:      "正常系: 完了",
+			url:       statusURL,
+			method:    "PATCH",
+			status:    http.StatusOK,
+			payload:   `{"status": "done"}`,
+			expected:  map[string]interface{}{"status": "done"},
+			started:   true,
+			completed: true,
+		},
+		{
+			name:     "異常系: 許可されていない遷移: 409",
+			url:      statusURL,
+			method:   "PATCH",
+			status:   http.StatusConflict,
+			payload:  `{"status": "blocked"}`,
+			expected: map[string]interface{}{"allowed_statuses": []interface{}{"todo"}},
+		},
+		{
+			name:     "異常系: 未定義のStatus: 400",
+			url:      statusURL,
+			method:   "PATCH",
+			status:   http.StatusBadRequest,
+			payload:  `{"status": "finished"}`,
+			expected: map[string]interface{}{"valid_statuses": []interface{}{"todo", "in_progress", "blocked", "done", "cancelled"}},
+		},
+		{
+			name:    "正常系: 再開",
+			url:     statusURL,
+			method:  "PATCH",
+			status:  http.StatusOK,
+			payload: `{"status": "todo"}`,
+			started: true,
+		},
+		{
+			name:    "異常系: 不正なワークフロー: 400",
+			url:     "/me/workflow",
+			method:  "PUT",
+			status:  http.StatusBadRequest,
+			payload: `{"states": ["open"], "transitions": {"open": ["closed"]}}`,
+		},
+		{
+			name:     "正常系: ワークフローの変更",
+			url:      "/me/workflow",
+			method:   "PUT",
+			status:   http.StatusOK,
+			payload:  `{"states": ["open", "closed"], "transitions": {"open": ["closed"]}, "completed_states": ["closed"]}`,
+			expected: map[string]interface{}{"states": []interface{}{"open", "closed"}},
+		},
+		{
+			name:      "正常系: ワークフローに存在しないStatusからの遷移",
+			url:       statusURL,
+			method:    "PATCH",
+			status:    http.StatusOK,
+			payload:   `{"status": "closed"}`,
+			expected:  map[string]interface{}{"status": "closed"},
+			started:   true,
+			completed: true,
+		},
+		{
+			name:     "異常系: 変更後のワークフローの遷移: 409",
+			url:      statusURL,
+			method:   "PATCH",
+			status:   http.StatusConflict,
+			payload:  `{"status": "open"}`,
+			expected: map[string]interface{}{"allowed_statuses": []interface{}{}},
+		},
+		{
+			name:     "正常系: ワークフローのリセット",
+			url:      "/me/workflow",
+			method:   "DELETE",
+			status:   http.StatusOK,
+			expected: map[string]interface{}{"states": []interface{}{"todo", "in_progress", "blocked", "done", "cancelled"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(caseNameHelper(t, c.name, c.method, c.url), func(t *testing.T) {
+			req, err := http.NewRequest(c.method, ts.URL+c.url, bytes.NewBuffer([]byte(c.payload)))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			req.Header.Set("Authorization", auth)
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != c.status {
+				t.Fatalf("Expected status code %v, got %v", c.status, res.StatusCode)
+			}
+			resData := map[string]interface{}{}
+			json.NewDecoder(res.Body).Decode(&resData)
+			for key, want := range c.expected {
+				got, _ := json.Marshal(resData[key])
+				wantJSON, _ := json.Marshal(want)
+				if string(got) != string(wantJSON) {
+					t.Fatalf("%s: want %s, got %s", key, wantJSON, got)
+				}
+			}
+			if c.method == "PATCH" && c.status == http.StatusOK {
+				if (resData["started_at"] != nil) != c.started {
+					t.Fatalf("started_at: want recorded = %v, got %v", c.started, resData["started_at"])
+				}
+				if (resData["completed_at"] != nil) != c.completed {
+					t.Fatalf("completed_at: want recorded = %v, got %v", c.completed, resData["completed_at"])
+				}
+			}
+		})
+	}
+}
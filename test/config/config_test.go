@@ -0,0 +1,133 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/lib/config"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  addr: 0.0.0.0:9000
+  read_timeout: 3s
+db:
+  dsn: host=db dbname=todo_app
+auth:
+  secret: `+testSecret+`
+`)
+
+	cases := []struct {
+		name     string
+		path     string
+		env      map[string]string
+		isError  bool
+		errorMsg string
+		check    func(cfg config.Config) bool
+	}{
+		{
+			name: "正常系: デフォルト値",
+			env:  map[string]string{"TODO_AUTH_SECRET": testSecret},
+			check: func(cfg config.Config) bool {
+				return cfg.Server.Addr == "localhost:8080" && cfg.Log.Level == config.LogLevelInfo
+			},
+		},
+		{
+			name: "正常系: 設定ファイル",
+			path: path,
+			check: func(cfg config.Config) bool {
+				return cfg.Server.Addr == "0.0.0.0:9000" &&
+					cfg.Server.ReadTimeout == 3*time.Second &&
+					cfg.DB.DSN == "host=db dbname=todo_app"
+			},
+		},
+		{
+			name: "正常系: 環境変数が設定ファイルより優先",
+			path: path,
+			env:  map[string]string{"TODO_ADDR": ":7000", "TODO_ACCESS_TOKEN_TTL": "5m"},
+			check: func(cfg config.Config) bool {
+				return cfg.Server.Addr == ":7000" && cfg.Auth.AccessTokenTTL == 5*time.Minute
+			},
+		},
+		{
+			name: "正常系: 署名鍵未設定の場合は生成",
+			check: func(cfg config.Config) bool {
+				return len(cfg.Auth.Secret) >= 32
+			},
+		},
+		{
+			name:     "異常系: 不正なログレベル",
+			env:      map[string]string{"TODO_LOG_LEVEL": "verbose"},
+			isError:  true,
+			errorMsg: "log.level",
+		},
+		{
+			name:     "異常系: 短い署名鍵",
+			env:      map[string]string{"TODO_AUTH_SECRET": "short"},
+			isError:  true,
+			errorMsg: "auth.secret",
+		},
+		{
+			name:     "異常系: ゴミ箱の保持期間",
+			env:      map[string]string{"TODO_TRASH_RETENTION": "0s"},
+			isError:  true,
+			errorMsg: "trash.retention",
+		},
+		{
+			name:     "異常系: 失効済みトークンの削除間隔",
+			env:      map[string]string{"TODO_TOKEN_PURGE_INTERVAL": "0s"},
+			isError:  true,
+			errorMsg: "auth.purge_interval",
+		},
+		{
+			name:     "異常系: 不正な期間",
+			env:      map[string]string{"TODO_READ_TIMEOUT": "ten seconds"},
+			isError:  true,
+			errorMsg: "TODO_READ_TIMEOUT",
+		},
+		{
+			name:     "異常系: 存在しない設定ファイル",
+			path:     filepath.Join(t.TempDir(), "missing.yaml"),
+			isError:  true,
+			errorMsg: "missing.yaml",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			os.Unsetenv("TODO_CONFIG_FILE")
+			for k, v := range c.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := config.Load(c.path)
+			if c.isError {
+				if err == nil || !strings.Contains(err.Error(), c.errorMsg) {
+					t.Fatalf("Expected error containing %q, got %v", c.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !c.check(cfg) {
+				t.Fatalf("Unexpected config: %+v", cfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/util"
+	"github.com/Z-me/practice-todo-api/migration"
+)
+
+func TestMigrationFiles(t *testing.T) {
+	// Note: 全てのドライバーに同じバージョンのマイグレーションが存在すること
+	expected, err := migration.Load(config.DriverPostgres)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, driver := range []string{config.DriverMySQL, config.DriverSQLite} {
+		t.Run(driver, func(t *testing.T) {
+			migrations, err := migration.Load(driver)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(migrations) != len(expected) {
+				t.Fatalf("Length: want %v migrations, got %v", len(expected), len(migrations))
+			}
+			for i, m := range migrations {
+				if m.Version != expected[i].Version || m.Name != expected[i].Name {
+					t.Fatalf("want %03d_%s, got %03d_%s", expected[i].Version, expected[i].Name, m.Version, m.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	cfg := config.Default()
+	cfg.DB.Driver = config.DriverSQLite
+	cfg.DB.DSN = ":memory:"
+	cfg.Log.Level = config.LogLevelError
+	dbObj, err := util.OpenDB(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer util.CloseDB(dbObj)
+
+	migrator, err := migration.New(dbObj, cfg.DB.Driver)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := migrator.CheckUpToDate(); !errors.Is(err, migration.ErrSchemaBehind) {
+		t.Fatalf("CheckUpToDate: want ErrSchemaBehind, got %v", err)
+	}
+
+	steps := []struct {
+		name     string
+		run      func() error
+		expected int
+	}{
+		{name: "up", run: migrator.Up, expected: migrator.Latest()},
+		{name: "down", run: migrator.Down, expected: migrator.Latest() - 1},
+		{name: "to 3", run: func() error { return migrator.To(3) }, expected: 3},
+		{name: "to 0", run: func() error { return migrator.To(0) }, expected: 0},
+		{name: "up again", run: migrator.Up, expected: migrator.Latest()},
+		{name: "force 2", run: func() error { return migrator.Force(2) }, expected: 2},
+		{name: "force latest", run: func() error { return migrator.Force(migrator.Latest()) }, expected: migrator.Latest()},
+	}
+	for _, s := range steps {
+		if err := s.run(); err != nil {
+			t.Fatalf("[%s] Expected no error, got %v", s.name, err)
+		}
+		current, err := migrator.Current()
+		if err != nil {
+			t.Fatalf("[%s] Expected no error, got %v", s.name, err)
+		}
+		if current != s.expected {
+			t.Fatalf("[%s] Version: want %v, got %v", s.name, s.expected, current)
+		}
+	}
+	if err := migrator.CheckUpToDate(); err != nil {
+		t.Fatalf("CheckUpToDate: Expected no error, got %v", err)
+	}
+	if err := migrator.To(999); err == nil {
+		t.Fatalf("To: want error for unknown version")
+	}
+}
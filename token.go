@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/repository"
+)
+
+// runTokenPurge は有効期限が切れた失効済みのリフレッシュトークンを一定間隔で削除する
+// ctx が終了するまで処理を続ける
+func runTokenPurge(ctx context.Context, cfg config.AuthConfig, tokens repository.TokenRepository) {
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		count, err := tokens.PurgeRevokedTokens(ctx, time.Now())
+		if err != nil {
+			log.Println("failed to purge revoked tokens:", err)
+		} else if count > 0 {
+			log.Printf("purged %d revoked tokens", count)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
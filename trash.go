@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Z-me/practice-todo-api/lib/config"
+	"github.com/Z-me/practice-todo-api/lib/repository"
+)
+
+// runTrashPurge は保持期間を過ぎたゴミ箱のTodoを一定間隔で完全に削除する
+// ctx が終了するまで処理を続ける
+func runTrashPurge(ctx context.Context, cfg config.TrashConfig, todos repository.TodoRepository) {
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		count, err := todos.PurgeTrash(ctx, time.Now().Add(-cfg.Retention))
+		if err != nil {
+			log.Println("failed to purge trash:", err)
+		} else if count > 0 {
+			log.Printf("purged %d items from trash", count)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}